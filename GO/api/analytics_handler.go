@@ -0,0 +1,128 @@
+package api
+
+import (
+	"net/http"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/Halturshik/EM-test-task/GO/database"
+	"github.com/Halturshik/EM-test-task/GO/logger"
+)
+
+// @Summary Аналитика платформы по месяцам
+// @Description Возвращает по каждому месяцу периода MRR (ежемесячную выручку), количество активных, новых, отмененных, повышенных и пониженных подписок, а также распределение подписок по ценовым уровням. Показатели закрытых месяцев кэшируются и пересчитываются при изменении подписок, затрагивающих эти месяцы
+// @Tags admin
+// @Produce json
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Param from query string true "Начало периода (MM-YYYY)"
+// @Param to query string true "Окончание периода (MM-YYYY)"
+// @Param service_name query string false "Название сервиса"
+// @Success 200 {object} api.AnalyticsResponse "Показатели по месяцам"
+// @Failure 400 {object} api.ErrorResponse "Некорректные данные запроса"
+// @Failure 401 {object} api.ErrorResponse "Не указаны или недействительны учетные данные"
+// @Failure 403 {object} api.ErrorResponse "Недостаточно прав"
+// @Failure 429 {object} api.ErrorResponse "Превышен лимит запросов"
+// @Failure 500 {object} api.ErrorResponse "Внутренняя ошибка сервера"
+// @Router /admin/analytics [get]
+func (api *API) GetAnalyticsHandler(w http.ResponseWriter, r *http.Request) {
+	fromStr := strings.TrimSpace(r.URL.Query().Get("from"))
+	toStr := strings.TrimSpace(r.URL.Query().Get("to"))
+	if fromStr == "" || toStr == "" {
+		logger.WarnContext(r.Context(), "Ошибка: не указан период для аналитики")
+		writeJSON(w, http.StatusBadRequest, map[string]any{"error": "Не указан период для аналитики"})
+		return
+	}
+
+	fromDate, err := time.Parse("01-2006", fromStr)
+	if err != nil {
+		logger.WarnContext(r.Context(), "Ошибка: некорректный формат даты начала периода аналитики")
+		writeJSON(w, http.StatusBadRequest, map[string]any{"error": "Неверный формат даты начала периода (используйте месяц-год)"})
+		return
+	}
+
+	toDate, err := time.Parse("01-2006", toStr)
+	if err != nil {
+		logger.WarnContext(r.Context(), "Ошибка: некорректный формат даты окончания периода аналитики")
+		writeJSON(w, http.StatusBadRequest, map[string]any{"error": "Неверный формат даты окончания периода (используйте месяц-год)"})
+		return
+	}
+
+	if toDate.Before(fromDate) {
+		logger.WarnContext(r.Context(), "Ошибка: дата окончания периода аналитики раньше даты начала")
+		writeJSON(w, http.StatusBadRequest, map[string]any{"error": "Дата окончания периода не может быть раньше даты начала периода"})
+		return
+	}
+
+	now := time.Now()
+	if toDate.After(time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)) {
+		logger.WarnContext(r.Context(), "Ошибка: дата окончания периода аналитики больше текущего месяца")
+		writeJSON(w, http.StatusBadRequest, map[string]any{"error": "Дата окончания периода не может быть больше текущего месяца"})
+		return
+	}
+
+	if toDate.After(fromDate.AddDate(0, 35, 0)) {
+		logger.WarnContext(r.Context(), "Ошибка: слишком длинный период аналитики")
+		writeJSON(w, http.StatusBadRequest, map[string]any{"error": "Период аналитики не может превышать 36 месяцев"})
+		return
+	}
+
+	serviceName := strings.TrimSpace(r.URL.Query().Get("service_name"))
+	if serviceName != "" {
+		reSN := regexp.MustCompile(`^[A-Za-z0-9 ]+$`)
+		if !reSN.MatchString(serviceName) {
+			logger.WarnContext(r.Context(), "Ошибка: в названии сервиса используются недопустимые символы")
+			writeJSON(w, http.StatusBadRequest, map[string]any{"error": "Недопустимое название сервиса: используйте только буквы, цифры и пробелы"})
+			return
+		}
+	}
+
+	metrics, err := api.Store.GetPlatformAnalytics(r.Context(), fromDate, toDate)
+	if err != nil {
+		logger.ErrorContext(r.Context(), "Ошибка при расчете аналитики: %v", err)
+		writeJSON(w, http.StatusInternalServerError, map[string]any{"error": "Ошибка при расчете аналитики. Повторите попытку позже"})
+		return
+	}
+
+	type analyticsMonth struct {
+		Month      string                    `json:"month"`
+		Cached     bool                      `json:"cached"`
+		MRR        int                       `json:"mrr"`
+		Active     int                       `json:"active"`
+		New        int                       `json:"new"`
+		Cancelled  int                       `json:"cancelled"`
+		Upgraded   int                       `json:"upgraded"`
+		Downgraded int                       `json:"downgraded"`
+		Services   []database.ServiceMetrics `json:"services"`
+	}
+
+	months := make([]analyticsMonth, 0, len(metrics))
+	for _, m := range metrics {
+		month := analyticsMonth{
+			Month:    m.Month.Format("01-2006"),
+			Cached:   m.Cached,
+			Services: []database.ServiceMetrics{},
+		}
+		for _, s := range m.Services {
+			if serviceName != "" && s.ServiceName != serviceName {
+				continue
+			}
+			month.Services = append(month.Services, s)
+			month.MRR += s.MRR
+			month.Active += s.Active
+			month.New += s.New
+			month.Cancelled += s.Cancelled
+			month.Upgraded += s.Upgraded
+			month.Downgraded += s.Downgraded
+		}
+		months = append(months, month)
+	}
+
+	writeJSON(w, http.StatusOK, map[string]any{
+		"from":   fromDate.Format("01-2006"),
+		"to":     toDate.Format("01-2006"),
+		"months": months,
+	})
+	logger.InfoContext(r.Context(), "Выдана аналитика за период %s - %s", fromStr, toStr)
+}
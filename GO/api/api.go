@@ -9,11 +9,20 @@ package api
 // @contact.name Artem
 // @contact.email disaer21@yandex.ru
 
+// @securityDefinitions.apikey ApiKeyAuth
+// @in header
+// @name X-API-Key
+
+// @securityDefinitions.apikey BearerAuth
+// @in header
+// @name Authorization
+
 import (
 	"context"
 	"time"
 
 	"github.com/Halturshik/EM-test-task/GO/database"
+	"github.com/Halturshik/EM-test-task/config"
 	"github.com/go-chi/chi/v5"
 	"github.com/google/uuid"
 	httpSwagger "github.com/swaggo/http-swagger"
@@ -21,33 +30,134 @@ import (
 
 type Store interface {
 	CreateSubscription(ctx context.Context, s *database.Subs) error
-	UpdateSubscription(ctx context.Context, userID uuid.UUID, serviceName string, newPrice *int, newEndDate *time.Time, newEndDateProvided bool) (bool, bool, string, error)
-	DeleteSubscription(ctx context.Context, userID uuid.UUID, serviceName string, startDate time.Time) error
+	UpdateSubscription(ctx context.Context, userID uuid.UUID, serviceName string, newPrice *int, newEndDate *time.Time, newEndDateProvided bool, expectedVersion *int64) (bool, bool, string, error)
+	PatchSubscription(ctx context.Context, userID uuid.UUID, serviceName string, patch database.SubPatch, expectedVersion *int64) (database.PatchResult, error)
+	DeleteSubscription(ctx context.Context, userID uuid.UUID, serviceName string, startDate time.Time, expectedVersion *int64) error
 	GetSubscriptions(ctx context.Context, userID uuid.UUID, serviceName string, status string, limit int, offset int) ([]database.Subs, error)
+	RestoreSubscription(ctx context.Context, userID uuid.UUID, serviceName string, startDate time.Time) error
+	CreateWebhook(ctx context.Context, w *database.Webhook) error
+	GetWebhooks(ctx context.Context) ([]database.Webhook, error)
+	GetWebhook(ctx context.Context, id int) (*database.Webhook, error)
+	UpdateWebhook(ctx context.Context, id int, patch database.WebhookPatch) (*database.Webhook, error)
+	DeleteWebhook(ctx context.Context, id int) error
+	GetWebhookDeliveries(ctx context.Context, webhookID int, limit int, offset int) ([]database.WebhookDelivery, error)
+	EnqueueTestWebhookEvent(ctx context.Context, webhookID int) (int64, error)
+	GetDeadLetters(ctx context.Context, limit int, offset int) ([]database.WebhookDelivery, error)
+	GetSubscriptionEvents(ctx context.Context, userID uuid.UUID, serviceName string, limit int, offset int) ([]database.SubEvent, error)
+	GetSubscriptionEventCursor(ctx context.Context) (database.EventCursor, error)
+	GetSubscriptionEventsBetween(ctx context.Context, from, to database.EventCursor, after database.EventPosition, userID *uuid.UUID, limit int) ([]database.SubEvent, error)
 	CalculateTotalSubscriptionCost(ctx context.Context, userID uuid.UUID, serviceName string, from, to time.Time) (int, string, error)
+	GetMonthlySpendReport(ctx context.Context, userID uuid.UUID, from, to time.Time) ([]database.ReportMonth, error)
+	GetPlatformAnalytics(ctx context.Context, from, to time.Time) ([]database.MonthMetrics, error)
+	GetCohortRetention(ctx context.Context, from, to time.Time, serviceName string) ([]database.CohortRow, error)
+	ForecastSubscriptionCost(ctx context.Context, userID uuid.UUID, serviceName string, from time.Time, months int) ([]database.ForecastMonth, error)
 	SyncSubscriptionPrices(ctx context.Context) error
+	SetBudget(ctx context.Context, userID uuid.UUID, serviceName *string, monthlyLimit int) error
+	DeleteBudget(ctx context.Context, userID uuid.UUID, serviceName *string) error
+	GetBudgetStatuses(ctx context.Context, userID uuid.UUID, month time.Time) ([]database.BudgetStatus, error)
+	RecordBudgetAlerts(ctx context.Context, userID *uuid.UUID, month time.Time) ([]database.BudgetStatus, error)
+	SetUserContact(ctx context.Context, userID uuid.UUID, email string) error
+	ReserveIdempotencyKey(ctx context.Context, owner string, key string, requestHash string, ttl time.Duration) (*database.IdempotencyRecord, error)
+	SaveIdempotencyResponse(ctx context.Context, owner string, key string, statusCode int, contentType string, body []byte) error
+	DeleteIdempotencyKey(ctx context.Context, owner string, key string) error
+	CreateAPIKey(ctx context.Context, k *database.APIKey, keyHash string) error
+	EnsureAPIKey(ctx context.Context, k *database.APIKey, keyHash string) error
+	AuthenticateAPIKey(ctx context.Context, keyHash string) (*database.APIKey, error)
+	GetAPIKeys(ctx context.Context) ([]database.APIKey, error)
+	RevokeAPIKey(ctx context.Context, id int) error
+	GetRolePermissions(ctx context.Context) (map[string][]string, error)
+	Ping(ctx context.Context) error
+	GetMigrationVersions(ctx context.Context) (int64, int64, error)
 }
 
 type API struct {
-	Store Store
+	Store  Store
+	Config *config.Config
+	JWT    *JWTVerifier
+
+	policy *rolePolicy
 }
 
-func NewAPI(store Store) *API {
-	return &API{Store: store}
+func NewAPI(store Store, cfg *config.Config, jwtVerifier *JWTVerifier) *API {
+	return &API{Store: store, Config: cfg, JWT: jwtVerifier, policy: &rolePolicy{}}
 }
 
 func (api *API) Init(r *chi.Mux) {
-	r.Route("/subscriptions", func(r chi.Router) {
-		r.Post("/", api.CreateSubscriptionHandler)
-	})
+	read := RequirePermission(database.PermSubscriptionsRead)
+	write := RequirePermission(database.PermSubscriptionsWrite)
+	reports := RequirePermission(database.PermReportsRead)
+	writeLimit := api.RateLimit("write")
+	reportsLimit := api.RateLimit("reports")
+
+	r.Get("/healthz", api.HealthzHandler)
+	r.Get("/readyz", api.ReadyzHandler)
+	r.Handle("/metrics", MetricsHandler())
+
+	r.Group(func(r chi.Router) {
+		r.Use(api.RateLimit("ip"))
+		r.Use(api.AuthMiddleware)
+		r.Use(api.RateLimit("default"))
+
+		r.With(read).Get("/events/stream", api.EventsStreamHandler)
+
+		r.Group(func(r chi.Router) {
+			r.Use(api.TimeoutMiddleware)
+
+			r.Route("/subscriptions", func(r chi.Router) {
+				r.With(write, writeLimit, api.IdempotencyMiddleware).Post("/", api.CreateSubscriptionHandler)
+			})
+
+			r.Route("/users/{user_id}/subscriptions", func(r chi.Router) {
+				r.Use(RequireSameUser)
+				r.With(read).Get("/", api.GetSubscriptionsHandler)
+				r.With(read).Get("/{service_name}", api.GetSubscriptionsHandler)
+				r.With(write, writeLimit, api.IdempotencyMiddleware).Put("/{service_name}", api.UpdateSubscriptionHandler)
+				r.With(write, writeLimit, api.IdempotencyMiddleware).Patch("/{service_name}", api.PatchSubscriptionHandler)
+				r.With(write, writeLimit).Delete("/{service_name}", api.DeleteSubscriptionHandler)
+				r.With(reports, reportsLimit).Post("/{service_name}/total", api.GetTotalSubscriptionCostHandler)
+				r.With(read).Get("/{service_name}/events", api.GetSubscriptionEventsHandler)
+			})
+
+			r.With(write, RequireSameUser, writeLimit).Put("/users/{user_id}/contact", api.SetContactHandler)
+			r.With(reports, RequireSameUser, reportsLimit).Get("/users/{user_id}/forecast", api.GetForecastHandler)
+			r.With(reports, RequireSameUser, reportsLimit).Get("/users/{user_id}/report", api.GetReportHandler)
+
+			r.Route("/users/{user_id}/budget", func(r chi.Router) {
+				r.Use(RequireSameUser)
+				r.With(write, writeLimit).Put("/", api.SetBudgetHandler)
+				r.With(reports, reportsLimit).Get("/", api.GetBudgetHandler)
+				r.With(write, writeLimit).Delete("/", api.DeleteBudgetHandler)
+			})
+
+			r.Route("/webhooks", func(r chi.Router) {
+				r.Use(RequirePermission(database.PermWebhooksManage))
+				r.Post("/", api.CreateWebhookHandler)
+				r.Get("/", api.GetWebhooksHandler)
+				r.Get("/{webhook_id}", api.GetWebhookHandler)
+				r.Patch("/{webhook_id}", api.UpdateWebhookHandler)
+				r.Delete("/{webhook_id}", api.DeleteWebhookHandler)
+				r.Get("/{webhook_id}/deliveries", api.GetWebhookDeliveriesHandler)
+				r.Post("/{webhook_id}/test", api.TestWebhookHandler)
+			})
+
+			r.Route("/admin", func(r chi.Router) {
+				r.With(RequirePermission(database.PermSubscriptionsRestore)).Post("/users/{user_id}/subscriptions/{service_name}/restore", api.RestoreSubscriptionHandler)
+				r.With(RequirePermission(database.PermWebhooksManage)).Get("/webhooks/dead-letters", api.GetDeadLettersHandler)
 
-	r.Route("/users/{user_id}/subscriptions", func(r chi.Router) {
-		r.Get("/", api.GetSubscriptionsHandler)
-		r.Get("/{service_name}", api.GetSubscriptionsHandler)
-		r.Put("/{service_name}", api.UpdateSubscriptionHandler)
-		r.Delete("/{service_name}", api.DeleteSubscriptionHandler)
-		r.Post("/{service_name}/total", api.GetTotalSubscriptionCostHandler)
+				r.Group(func(r chi.Router) {
+					r.Use(RequirePermission(database.PermAnalyticsRead), reportsLimit)
+					r.Get("/analytics", api.GetAnalyticsHandler)
+					r.Get("/analytics/cohorts", api.GetCohortsHandler)
+				})
 
+				r.Group(func(r chi.Router) {
+					r.Use(RequirePermission(database.PermAPIKeysManage))
+					r.Post("/api-keys", api.CreateAPIKeyHandler)
+					r.Get("/api-keys", api.GetAPIKeysHandler)
+					r.Delete("/api-keys/{key_id}", api.RevokeAPIKeyHandler)
+				})
+			})
+		})
 	})
 
 	r.Get("/swagger/*", httpSwagger.Handler())
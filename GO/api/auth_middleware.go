@@ -0,0 +1,191 @@
+package api
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"log/slog"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/Halturshik/EM-test-task/GO/database"
+	"github.com/Halturshik/EM-test-task/GO/logger"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+)
+
+type principal struct {
+	Actor       string
+	Permissions []string
+	UserID      *uuid.UUID
+}
+
+type principalContextKey struct{}
+
+func principalFromContext(ctx context.Context) *principal {
+	p, _ := ctx.Value(principalContextKey{}).(*principal)
+	return p
+}
+
+func (p *principal) hasPermission(permission string) bool {
+	return slices.Contains(p.Permissions, permission)
+}
+
+// boundUserID возвращает пользователя, которым ограничен запрос: subject
+// JWT без разрешения users:any. Для ролей с users:any и для API-ключей,
+// которые не привязаны к пользователю, — nil.
+func (p *principal) boundUserID() *uuid.UUID {
+	if p == nil || p.hasPermission(database.PermUsersAny) {
+		return nil
+	}
+	return p.UserID
+}
+
+func (api *API) AuthMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var p *principal
+		var ok bool
+
+		if authHeader := strings.TrimSpace(r.Header.Get("Authorization")); authHeader != "" {
+			p, ok = api.authenticateBearer(w, r, authHeader)
+		} else {
+			p, ok = api.authenticateAPIKey(w, r)
+		}
+		if !ok {
+			return
+		}
+
+		ctx := context.WithValue(r.Context(), principalContextKey{}, p)
+		ctx = database.WithAuditInfo(ctx, database.AuditInfo{
+			Actor:     p.Actor,
+			RequestID: requestIDFromContext(r.Context()),
+		})
+		if p.UserID != nil {
+			ctx = logger.WithFields(ctx, slog.String("user_id", p.UserID.String()))
+		}
+
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+func (api *API) authenticateAPIKey(w http.ResponseWriter, r *http.Request) (*principal, bool) {
+	rawKey := strings.TrimSpace(r.Header.Get("X-API-Key"))
+	if rawKey == "" {
+		logger.WarnContext(r.Context(), "Ошибка: запрос без учетных данных к %s %s", r.Method, r.URL.Path)
+		writeJSON(w, http.StatusUnauthorized, map[string]any{"error": "Требуется API-ключ в заголовке X-API-Key или токен в заголовке Authorization"})
+		return nil, false
+	}
+
+	key, err := api.Store.AuthenticateAPIKey(r.Context(), hashAPIKey(rawKey))
+	if errors.Is(err, database.ErrAPIKeyNotFound) {
+		logger.WarnContext(r.Context(), "Ошибка: недействительный API-ключ для %s %s", r.Method, r.URL.Path)
+		writeJSON(w, http.StatusUnauthorized, map[string]any{"error": "Недействительный или отозванный API-ключ"})
+		return nil, false
+	}
+	if err != nil {
+		logger.ErrorContext(r.Context(), "Ошибка проверки API-ключа: %v", err)
+		writeJSON(w, http.StatusInternalServerError, map[string]any{"error": "Не удалось проверить API-ключ. Повторите попытку позже"})
+		return nil, false
+	}
+
+	permissions := key.Scopes
+	if slices.Contains(key.Scopes, database.RoleAdmin) {
+		permissions = database.Permissions
+	}
+
+	return &principal{Actor: "api_key:" + strconv.Itoa(key.ID), Permissions: permissions}, true
+}
+
+func (api *API) authenticateBearer(w http.ResponseWriter, r *http.Request, authHeader string) (*principal, bool) {
+	scheme, token, found := strings.Cut(authHeader, " ")
+	if !found || !strings.EqualFold(scheme, "Bearer") || strings.TrimSpace(token) == "" {
+		logger.WarnContext(r.Context(), "Ошибка: некорректный заголовок Authorization для %s %s", r.Method, r.URL.Path)
+		writeJSON(w, http.StatusUnauthorized, map[string]any{"error": "Заголовок Authorization должен иметь вид Bearer <токен>"})
+		return nil, false
+	}
+
+	identity, err := api.JWT.Verify(strings.TrimSpace(token))
+	if err != nil {
+		logger.WarnContext(r.Context(), "Ошибка: недействительный JWT для %s %s: %v", r.Method, r.URL.Path, err)
+		writeJSON(w, http.StatusUnauthorized, map[string]any{"error": "Недействительный токен"})
+		return nil, false
+	}
+
+	roles := identity.Roles
+	if len(roles) == 0 {
+		roles = []string{database.RoleUser}
+	}
+
+	permissions, err := api.permissionsForRoles(r.Context(), roles)
+	if err != nil {
+		logger.ErrorContext(r.Context(), "Ошибка загрузки разрешений ролей: %v", err)
+		writeJSON(w, http.StatusInternalServerError, map[string]any{"error": "Не удалось проверить права доступа. Повторите попытку позже"})
+		return nil, false
+	}
+
+	return &principal{Actor: "user:" + identity.UserID.String(), Permissions: permissions, UserID: &identity.UserID}, true
+}
+
+func RequirePermission(permission string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			p := principalFromContext(r.Context())
+			if p == nil || !p.hasPermission(permission) {
+				logger.WarnContext(r.Context(), "Ошибка: недостаточно прав для %s %s (требуется %s)", r.Method, r.URL.Path, permission)
+				writeJSON(w, http.StatusForbidden, map[string]any{"error": "Недостаточно прав: требуется разрешение " + permission})
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+func RequireSameUser(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		bound := principalFromContext(r.Context()).boundUserID()
+		if bound != nil {
+			pathUserID, err := uuid.Parse(strings.TrimSpace(chi.URLParam(r, "user_id")))
+			if err != nil || pathUserID != *bound {
+				logger.WarnContext(r.Context(), "Ошибка: пользователь %s обращается к данным пользователя %s", bound, chi.URLParam(r, "user_id"))
+				writeJSON(w, http.StatusForbidden, map[string]any{"error": "Доступ к данным другого пользователя запрещен"})
+				return
+			}
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+func BootstrapAdminKey(ctx context.Context, store Store, rawKey string) error {
+	key := &database.APIKey{
+		Name:   "bootstrap",
+		Prefix: apiKeyPrefix(rawKey),
+		Scopes: []string{database.RoleAdmin},
+	}
+	return store.EnsureAPIKey(ctx, key, hashAPIKey(rawKey))
+}
+
+func generateAPIKey() (string, error) {
+	b := make([]byte, 24)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return "sk_" + hex.EncodeToString(b), nil
+}
+
+func hashAPIKey(rawKey string) string {
+	sum := sha256.Sum256([]byte(rawKey))
+	return hex.EncodeToString(sum[:])
+}
+
+func apiKeyPrefix(rawKey string) string {
+	if len(rawKey) > 11 {
+		return rawKey[:11]
+	}
+	return rawKey
+}
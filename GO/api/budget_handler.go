@@ -0,0 +1,194 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/Halturshik/EM-test-task/GO/database"
+	"github.com/Halturshik/EM-test-task/GO/logger"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+)
+
+// @Summary Установить бюджет
+// @Description Устанавливает месячный лимит расходов пользователя на все подписки или на подписки одного сервиса
+// @Tags budgets
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Param user_id path string true "UUID пользователя"
+// @Param body body api.SetBudgetRequest true "Лимит расходов"
+// @Success 200 {object} api.SetBudgetResponse "Бюджет установлен"
+// @Failure 400 {object} api.ErrorResponse "Некорректные данные запроса"
+// @Failure 401 {object} api.ErrorResponse "Не указаны или недействительны учетные данные"
+// @Failure 403 {object} api.ErrorResponse "Недостаточно прав"
+// @Failure 413 {object} api.ErrorResponse "Тело запроса превышает допустимый размер"
+// @Failure 429 {object} api.ErrorResponse "Превышен лимит запросов"
+// @Failure 500 {object} api.ErrorResponse "Внутренняя ошибка сервера"
+// @Router /users/{user_id}/budget [put]
+func (api *API) SetBudgetHandler(w http.ResponseWriter, r *http.Request) {
+	userID, err := uuid.Parse(strings.TrimSpace(chi.URLParam(r, "user_id")))
+	if err != nil {
+		logger.WarnContext(r.Context(), "Ошибка: некорректный формат uuid: %v", err)
+		writeJSON(w, http.StatusBadRequest, map[string]any{"error": "Некорректный формат идентификатора пользователя"})
+		return
+	}
+
+	var req struct {
+		MonthlyLimit int     `json:"monthly_limit"`
+		ServiceName  *string `json:"service_name"`
+	}
+
+	if !decodeJSON(w, r, &req) {
+		return
+	}
+
+	if req.MonthlyLimit <= 0 {
+		logger.WarnContext(r.Context(), "Ошибка: некорректный лимит бюджета")
+		writeJSON(w, http.StatusBadRequest, map[string]any{"error": "Месячный лимит должен быть положительным числом"})
+		return
+	}
+
+	serviceName, ok := budgetServiceName(req.ServiceName)
+	if !ok {
+		logger.WarnContext(r.Context(), "Ошибка: в названии сервиса используются недопустимые символы")
+		writeJSON(w, http.StatusBadRequest, map[string]any{"error": "Недопустимое название сервиса: используйте только буквы, цифры и пробелы"})
+		return
+	}
+
+	if err := api.Store.SetBudget(r.Context(), userID, serviceName, req.MonthlyLimit); err != nil {
+		logger.ErrorContext(r.Context(), "Ошибка: не удалось сохранить бюджет: %v", err)
+		writeJSON(w, http.StatusInternalServerError, map[string]any{"error": "Не удалось сохранить бюджет. Повторите попытку позже"})
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]any{"message": "Бюджет успешно установлен"})
+	logger.InfoContext(r.Context(), "Установлен бюджет пользователя %s: service=%v limit=%d", userID, serviceName, req.MonthlyLimit)
+
+	api.checkBudgets(context.WithoutCancel(r.Context()), userID)
+}
+
+// @Summary Получить состояние бюджета
+// @Description Возвращает установленные лимиты пользователя и прогноз расходов на подписки в текущем месяце
+// @Tags budgets
+// @Produce json
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Param user_id path string true "UUID пользователя"
+// @Success 200 {array} api.BudgetStatusResponse "Состояние бюджетов"
+// @Failure 400 {object} api.ErrorResponse "Некорректный UUID пользователя"
+// @Failure 401 {object} api.ErrorResponse "Не указаны или недействительны учетные данные"
+// @Failure 403 {object} api.ErrorResponse "Недостаточно прав"
+// @Failure 429 {object} api.ErrorResponse "Превышен лимит запросов"
+// @Failure 500 {object} api.ErrorResponse "Внутренняя ошибка сервера"
+// @Router /users/{user_id}/budget [get]
+func (api *API) GetBudgetHandler(w http.ResponseWriter, r *http.Request) {
+	userID, err := uuid.Parse(strings.TrimSpace(chi.URLParam(r, "user_id")))
+	if err != nil {
+		logger.WarnContext(r.Context(), "Ошибка: некорректный формат uuid: %v", err)
+		writeJSON(w, http.StatusBadRequest, map[string]any{"error": "Некорректный формат идентификатора пользователя"})
+		return
+	}
+
+	statuses, err := api.Store.GetBudgetStatuses(r.Context(), userID, time.Now())
+	if err != nil {
+		logger.ErrorContext(r.Context(), "Ошибка: не удалось получить состояние бюджета: %v", err)
+		writeJSON(w, http.StatusInternalServerError, map[string]any{"error": "Не удалось получить состояние бюджета. Повторите попытку позже"})
+		return
+	}
+
+	if len(statuses) == 0 {
+		writeJSON(w, http.StatusOK, map[string]any{"message": "Бюджет не установлен"})
+		return
+	}
+
+	writeJSON(w, http.StatusOK, statuses)
+	logger.InfoContext(r.Context(), "Выдано состояние бюджета пользователя %s: count=%d", userID, len(statuses))
+}
+
+// @Summary Удалить бюджет
+// @Description Удаляет общий лимит пользователя или лимит на подписки указанного сервиса
+// @Tags budgets
+// @Produce json
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Param user_id path string true "UUID пользователя"
+// @Param service_name query string false "Название сервиса (если не указано — удаляется общий лимит)"
+// @Success 200 {object} api.SetBudgetResponse "Бюджет удален"
+// @Failure 400 {object} api.ErrorResponse "Некорректные данные запроса"
+// @Failure 404 {object} api.ErrorResponse "Бюджет не найден"
+// @Failure 401 {object} api.ErrorResponse "Не указаны или недействительны учетные данные"
+// @Failure 403 {object} api.ErrorResponse "Недостаточно прав"
+// @Failure 429 {object} api.ErrorResponse "Превышен лимит запросов"
+// @Failure 500 {object} api.ErrorResponse "Внутренняя ошибка сервера"
+// @Router /users/{user_id}/budget [delete]
+func (api *API) DeleteBudgetHandler(w http.ResponseWriter, r *http.Request) {
+	userID, err := uuid.Parse(strings.TrimSpace(chi.URLParam(r, "user_id")))
+	if err != nil {
+		logger.WarnContext(r.Context(), "Ошибка: некорректный формат uuid: %v", err)
+		writeJSON(w, http.StatusBadRequest, map[string]any{"error": "Некорректный формат идентификатора пользователя"})
+		return
+	}
+
+	var rawServiceName *string
+	if r.URL.Query().Has("service_name") {
+		v := r.URL.Query().Get("service_name")
+		rawServiceName = &v
+	}
+
+	serviceName, ok := budgetServiceName(rawServiceName)
+	if !ok {
+		logger.WarnContext(r.Context(), "Ошибка: в названии сервиса используются недопустимые символы")
+		writeJSON(w, http.StatusBadRequest, map[string]any{"error": "Недопустимое название сервиса: используйте только буквы, цифры и пробелы"})
+		return
+	}
+
+	if err := api.Store.DeleteBudget(r.Context(), userID, serviceName); err != nil {
+		if errors.Is(err, database.ErrBudgetNotFound) {
+			logger.WarnContext(r.Context(), "Ошибка: бюджет не найден")
+			writeJSON(w, http.StatusNotFound, map[string]any{"error": "Бюджет не найден"})
+			return
+		}
+		logger.ErrorContext(r.Context(), "Ошибка при удалении бюджета: %v", err)
+		writeJSON(w, http.StatusInternalServerError, map[string]any{"error": "Ошибка при удалении бюджета. Повторите попытку позже"})
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]any{"message": "Бюджет успешно удален"})
+	logger.InfoContext(r.Context(), "Удален бюджет пользователя %s: service=%v", userID, serviceName)
+}
+
+func budgetServiceName(raw *string) (*string, bool) {
+	if raw == nil {
+		return nil, true
+	}
+
+	serviceName := strings.TrimSpace(*raw)
+	if serviceName == "" {
+		return nil, true
+	}
+
+	reSN := regexp.MustCompile(`^[A-Za-z0-9 ]+$`)
+	if !reSN.MatchString(serviceName) {
+		return nil, false
+	}
+
+	return &serviceName, true
+}
+
+func (api *API) checkBudgets(ctx context.Context, userID uuid.UUID) {
+	alerts, err := api.Store.RecordBudgetAlerts(ctx, &userID, time.Now())
+	if err != nil {
+		logger.ErrorContext(ctx, "Ошибка проверки бюджета пользователя %s: %v", userID, err)
+		return
+	}
+
+	for _, a := range alerts {
+		logger.WarnContext(ctx, "Превышен бюджет пользователя %s (service=%v): прогноз %d при лимите %d", userID, a.ServiceName, a.ProjectedMonthlyCost, a.MonthlyLimit)
+	}
+}
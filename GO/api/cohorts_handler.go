@@ -0,0 +1,161 @@
+package api
+
+import (
+	"encoding/csv"
+	"fmt"
+	"math"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Halturshik/EM-test-task/GO/database"
+	"github.com/Halturshik/EM-test-task/GO/logger"
+)
+
+// @Summary Когортный отчет об удержании подписок
+// @Description Группирует подписки по месяцу даты начала и сервису и показывает, какая доля подписок когорты остается активной через 1, 3, 6 и 12 месяцев. Для периодов, которые еще не наступили, значение равно null. Поддерживает выгрузку в CSV
+// @Tags admin
+// @Produce json
+// @Produce text/csv
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Param from query string true "Первый месяц когорт (MM-YYYY)"
+// @Param to query string true "Последний месяц когорт (MM-YYYY)"
+// @Param service_name query string false "Название сервиса"
+// @Param format query string false "Формат ответа: json или csv" default(json)
+// @Success 200 {array} api.CohortResponse "Когорты"
+// @Failure 400 {object} api.ErrorResponse "Некорректные данные запроса"
+// @Failure 401 {object} api.ErrorResponse "Не указаны или недействительны учетные данные"
+// @Failure 403 {object} api.ErrorResponse "Недостаточно прав"
+// @Failure 429 {object} api.ErrorResponse "Превышен лимит запросов"
+// @Failure 500 {object} api.ErrorResponse "Внутренняя ошибка сервера"
+// @Router /admin/analytics/cohorts [get]
+func (api *API) GetCohortsHandler(w http.ResponseWriter, r *http.Request) {
+	fromStr := strings.TrimSpace(r.URL.Query().Get("from"))
+	toStr := strings.TrimSpace(r.URL.Query().Get("to"))
+	if fromStr == "" || toStr == "" {
+		logger.WarnContext(r.Context(), "Ошибка: не указан период для когортного отчета")
+		writeJSON(w, http.StatusBadRequest, map[string]any{"error": "Не указан период для когортного отчета"})
+		return
+	}
+
+	fromDate, err := time.Parse("01-2006", fromStr)
+	if err != nil {
+		logger.WarnContext(r.Context(), "Ошибка: некорректный формат даты начала периода когортного отчета")
+		writeJSON(w, http.StatusBadRequest, map[string]any{"error": "Неверный формат даты начала периода (используйте месяц-год)"})
+		return
+	}
+
+	toDate, err := time.Parse("01-2006", toStr)
+	if err != nil {
+		logger.WarnContext(r.Context(), "Ошибка: некорректный формат даты окончания периода когортного отчета")
+		writeJSON(w, http.StatusBadRequest, map[string]any{"error": "Неверный формат даты окончания периода (используйте месяц-год)"})
+		return
+	}
+
+	if toDate.Before(fromDate) {
+		logger.WarnContext(r.Context(), "Ошибка: дата окончания периода когортного отчета раньше даты начала")
+		writeJSON(w, http.StatusBadRequest, map[string]any{"error": "Дата окончания периода не может быть раньше даты начала периода"})
+		return
+	}
+
+	serviceName := strings.TrimSpace(r.URL.Query().Get("service_name"))
+	if serviceName != "" {
+		reSN := regexp.MustCompile(`^[A-Za-z0-9 ]+$`)
+		if !reSN.MatchString(serviceName) {
+			logger.WarnContext(r.Context(), "Ошибка: в названии сервиса используются недопустимые символы")
+			writeJSON(w, http.StatusBadRequest, map[string]any{"error": "Недопустимое название сервиса: используйте только буквы, цифры и пробелы"})
+			return
+		}
+	}
+
+	format := strings.ToLower(strings.TrimSpace(r.URL.Query().Get("format")))
+	if format == "" {
+		format = "json"
+	}
+	if format != "json" && format != "csv" {
+		logger.WarnContext(r.Context(), "Ошибка: неизвестный формат когортного отчета: %s", format)
+		writeJSON(w, http.StatusBadRequest, map[string]any{"error": "Формат отчета должен быть json или csv"})
+		return
+	}
+
+	rows, err := api.Store.GetCohortRetention(r.Context(), fromDate, toDate, serviceName)
+	if err != nil {
+		logger.ErrorContext(r.Context(), "Ошибка при построении когортного отчета: %v", err)
+		writeJSON(w, http.StatusInternalServerError, map[string]any{"error": "Ошибка при построении когортного отчета. Повторите попытку позже"})
+		return
+	}
+
+	now := time.Now()
+	currentMonth := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+
+	type cohort struct {
+		Cohort      string              `json:"cohort"`
+		ServiceName string              `json:"service_name"`
+		Size        int                 `json:"size"`
+		Retention   map[string]*float64 `json:"retention"`
+	}
+
+	cohorts := make([]cohort, 0, len(rows))
+	for _, row := range rows {
+		c := cohort{
+			Cohort:      row.Cohort.Format("01-2006"),
+			ServiceName: row.ServiceName,
+			Size:        row.Size,
+			Retention:   make(map[string]*float64, len(database.CohortPeriods)),
+		}
+		for i, period := range database.CohortPeriods {
+			key := fmt.Sprintf("m%d", period)
+			if row.Cohort.AddDate(0, period, 0).After(currentMonth) || row.Size == 0 {
+				c.Retention[key] = nil
+				continue
+			}
+			fraction := math.Round(float64(row.Retained[i])/float64(row.Size)*10000) / 10000
+			c.Retention[key] = &fraction
+		}
+		cohorts = append(cohorts, c)
+	}
+
+	logger.InfoContext(r.Context(), "Выдан когортный отчет за период %s - %s в формате %s", fromStr, toStr, format)
+
+	if format == "json" {
+		writeJSON(w, http.StatusOK, cohorts)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="cohorts_%s_%s.csv"`, fromDate.Format("2006-01"), toDate.Format("2006-01")))
+	w.WriteHeader(http.StatusOK)
+
+	cw := csv.NewWriter(w)
+	header := []string{"cohort", "service_name", "size"}
+	for _, period := range database.CohortPeriods {
+		header = append(header, fmt.Sprintf("m%d", period))
+	}
+	if err := cw.Write(header); err != nil {
+		logger.ErrorContext(r.Context(), "Ошибка при записи когортного отчета в CSV: %v", err)
+		return
+	}
+
+	for _, c := range cohorts {
+		record := []string{c.Cohort, c.ServiceName, strconv.Itoa(c.Size)}
+		for _, period := range database.CohortPeriods {
+			value := ""
+			if v := c.Retention[fmt.Sprintf("m%d", period)]; v != nil {
+				value = strconv.FormatFloat(*v, 'f', 4, 64)
+			}
+			record = append(record, value)
+		}
+		if err := cw.Write(record); err != nil {
+			logger.ErrorContext(r.Context(), "Ошибка при записи когортного отчета в CSV: %v", err)
+			return
+		}
+	}
+
+	cw.Flush()
+	if err := cw.Error(); err != nil {
+		logger.ErrorContext(r.Context(), "Ошибка при записи когортного отчета в CSV: %v", err)
+	}
+}
@@ -0,0 +1,143 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/Halturshik/EM-test-task/config"
+)
+
+func testCORSConfig(origins ...string) *config.Config {
+	return &config.Config{
+		CORSAllowedOrigins: origins,
+		CORSAllowedMethods: []string{"GET", "POST", "PUT", "PATCH", "DELETE"},
+		CORSAllowedHeaders: []string{"Authorization", "Content-Type", "If-Match"},
+		CORSExposedHeaders: []string{"ETag", "Retry-After"},
+		CORSMaxAge:         10 * time.Minute,
+	}
+}
+
+func TestCORSMiddlewarePreflight(t *testing.T) {
+	tests := []struct {
+		name           string
+		cfg            *config.Config
+		origin         string
+		method         string
+		headers        string
+		wantStatus     int
+		wantAllowOrig  string
+		wantNextCalled bool
+	}{
+		{
+			name:          "разрешенный источник",
+			cfg:           testCORSConfig("https://app.example.com"),
+			origin:        "https://app.example.com",
+			method:        "PUT",
+			headers:       "Content-Type, If-Match",
+			wantStatus:    http.StatusNoContent,
+			wantAllowOrig: "https://app.example.com",
+		},
+		{
+			name:          "любой источник",
+			cfg:           testCORSConfig("*"),
+			origin:        "https://other.example.com",
+			method:        "GET",
+			wantStatus:    http.StatusNoContent,
+			wantAllowOrig: "*",
+		},
+		{
+			name:       "чужой источник",
+			cfg:        testCORSConfig("https://app.example.com"),
+			origin:     "https://evil.example.com",
+			method:     "GET",
+			wantStatus: http.StatusForbidden,
+		},
+		{
+			name:          "неразрешенный метод",
+			cfg:           testCORSConfig("https://app.example.com"),
+			origin:        "https://app.example.com",
+			method:        "TRACE",
+			wantStatus:    http.StatusForbidden,
+			wantAllowOrig: "https://app.example.com",
+		},
+		{
+			name:          "неразрешенный заголовок",
+			cfg:           testCORSConfig("https://app.example.com"),
+			origin:        "https://app.example.com",
+			method:        "POST",
+			headers:       "Content-Type, X-Debug",
+			wantStatus:    http.StatusForbidden,
+			wantAllowOrig: "https://app.example.com",
+		},
+		{
+			name:           "CORS выключен",
+			cfg:            testCORSConfig(),
+			origin:         "https://app.example.com",
+			method:         "GET",
+			wantStatus:     http.StatusOK,
+			wantNextCalled: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			nextCalled := false
+			handler := CORSMiddleware(tt.cfg)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				nextCalled = true
+			}))
+
+			r := httptest.NewRequest(http.MethodOptions, "/subscriptions", nil)
+			r.Header.Set("Origin", tt.origin)
+			r.Header.Set("Access-Control-Request-Method", tt.method)
+			if tt.headers != "" {
+				r.Header.Set("Access-Control-Request-Headers", tt.headers)
+			}
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, r)
+
+			if w.Code != tt.wantStatus {
+				t.Errorf("статус = %d, ожидалось %d", w.Code, tt.wantStatus)
+			}
+			if got := w.Header().Get("Access-Control-Allow-Origin"); got != tt.wantAllowOrig {
+				t.Errorf("Access-Control-Allow-Origin = %q, ожидалось %q", got, tt.wantAllowOrig)
+			}
+			if nextCalled != tt.wantNextCalled {
+				t.Errorf("вызов обработчика = %v, ожидалось %v", nextCalled, tt.wantNextCalled)
+			}
+			if tt.wantStatus == http.StatusNoContent {
+				if got := w.Header().Get("Access-Control-Allow-Methods"); got != "GET, POST, PUT, PATCH, DELETE" {
+					t.Errorf("Access-Control-Allow-Methods = %q", got)
+				}
+				if got := w.Header().Get("Access-Control-Max-Age"); got != "600" {
+					t.Errorf("Access-Control-Max-Age = %q, ожидалось 600", got)
+				}
+			}
+		})
+	}
+}
+
+func TestCORSMiddlewareSimpleRequest(t *testing.T) {
+	handler := CORSMiddleware(testCORSConfig("https://app.example.com"))(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	r := httptest.NewRequest(http.MethodGet, "/subscriptions", nil)
+	r.Header.Set("Origin", "https://app.example.com")
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, r)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("статус = %d, ожидалось 200", w.Code)
+	}
+	if got := w.Header().Get("Access-Control-Allow-Origin"); got != "https://app.example.com" {
+		t.Errorf("Access-Control-Allow-Origin = %q", got)
+	}
+	if got := w.Header().Get("Access-Control-Expose-Headers"); got != "ETag, Retry-After" {
+		t.Errorf("Access-Control-Expose-Headers = %q", got)
+	}
+	if got := w.Header().Values("Vary"); len(got) != 1 || got[0] != "Origin" {
+		t.Errorf("Vary = %v, ожидалось [Origin]", got)
+	}
+}
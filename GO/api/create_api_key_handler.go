@@ -0,0 +1,86 @@
+package api
+
+import (
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/Halturshik/EM-test-task/GO/database"
+	"github.com/Halturshik/EM-test-task/GO/logger"
+)
+
+// @Summary Выпустить API-ключ
+// @Description Создает API-ключ с указанными разрешениями (subscriptions:read, subscriptions:write, subscriptions:restore, reports:read, analytics:read, webhooks:manage, api_keys:manage) или со scope admin, дающим все разрешения. Ключ не привязан к пользователю: с разрешениями subscriptions:read и subscriptions:write он работает с данными любого пользователя, поэтому разрешение users:any ключам не выдается (оно действует только для ролей JWT). Ключ возвращается один раз в ответе, в базе хранится только его хэш
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Param body body api.CreateAPIKeyRequest true "Параметры ключа"
+// @Success 201 {object} api.CreateAPIKeyResponse "Ключ выпущен"
+// @Failure 400 {object} api.ErrorResponse "Некорректные данные запроса"
+// @Failure 401 {object} api.ErrorResponse "Не указаны или недействительны учетные данные"
+// @Failure 403 {object} api.ErrorResponse "Недостаточно прав"
+// @Failure 413 {object} api.ErrorResponse "Тело запроса превышает допустимый размер"
+// @Failure 429 {object} api.ErrorResponse "Превышен лимит запросов"
+// @Failure 500 {object} api.ErrorResponse "Внутренняя ошибка сервера"
+// @Router /admin/api-keys [post]
+func (api *API) CreateAPIKeyHandler(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Name   string   `json:"name"`
+		Scopes []string `json:"scopes"`
+	}
+
+	if !decodeJSON(w, r, &req) {
+		return
+	}
+
+	name := strings.TrimSpace(req.Name)
+	if name == "" || len(name) > 128 {
+		logger.WarnContext(r.Context(), "Ошибка: некорректное название API-ключа")
+		writeJSON(w, http.StatusBadRequest, map[string]any{"error": "Укажите название ключа (не более 128 символов)"})
+		return
+	}
+
+	if len(req.Scopes) == 0 {
+		logger.WarnContext(r.Context(), "Ошибка: не указаны разрешения API-ключа")
+		writeJSON(w, http.StatusBadRequest, map[string]any{"error": "Укажите хотя бы одно разрешение для ключа"})
+		return
+	}
+	for _, scope := range req.Scopes {
+		if !slices.Contains(database.APIKeyScopes, scope) {
+			logger.WarnContext(r.Context(), "Ошибка: неизвестное разрешение %q", scope)
+			writeJSON(w, http.StatusBadRequest, map[string]any{"error": "Неизвестное разрешение: " + scope})
+			return
+		}
+	}
+
+	rawKey, err := generateAPIKey()
+	if err != nil {
+		logger.ErrorContext(r.Context(), "Ошибка генерации API-ключа: %v", err)
+		writeJSON(w, http.StatusInternalServerError, map[string]any{"error": "Не удалось выпустить API-ключ. Повторите попытку позже"})
+		return
+	}
+
+	key := &database.APIKey{
+		Name:   name,
+		Prefix: apiKeyPrefix(rawKey),
+		Scopes: req.Scopes,
+	}
+
+	if err := api.Store.CreateAPIKey(r.Context(), key, hashAPIKey(rawKey)); err != nil {
+		logger.ErrorContext(r.Context(), "Ошибка: не удалось сохранить API-ключ: %v", err)
+		writeJSON(w, http.StatusInternalServerError, map[string]any{"error": "Не удалось выпустить API-ключ. Повторите попытку позже"})
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, map[string]any{
+		"id":         key.ID,
+		"name":       key.Name,
+		"key":        rawKey,
+		"prefix":     key.Prefix,
+		"scopes":     key.Scopes,
+		"created_at": key.CreatedAt,
+	})
+	logger.InfoContext(r.Context(), "Выпущен API-ключ %d (%s) с разрешениями %v", key.ID, key.Name, key.Scopes)
+}
@@ -1,7 +1,7 @@
 package api
 
 import (
-	"encoding/json"
+	"context"
 	"errors"
 	"net/http"
 	"regexp"
@@ -14,14 +14,22 @@ import (
 )
 
 // @Summary Создать подписку
-// @Description Создает новую подписку для пользователя
+// @Description Создает новую подписку для пользователя. При авторизации по JWT пользователь берется из токена, поле user_id можно не указывать
 // @Tags subscriptions
 // @Accept json
 // @Produce json
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Param Idempotency-Key header string false "Ключ идемпотентности для безопасного повтора запроса"
 // @Param subscription body api.CreateSubRequest true "Данные подписки"
 // @Success 201 {object} api.CreateSubResponse "Подписка успешно создана"
 // @Failure 400 {object} api.ErrorResponse "Ошибка валидации"
 // @Failure 409 {object} api.ErrorResponse "Подписка уже существует"
+// @Failure 422 {object} api.ErrorResponse "Ключ идемпотентности использован для другого запроса"
+// @Failure 401 {object} api.ErrorResponse "Не указаны или недействительны учетные данные"
+// @Failure 403 {object} api.ErrorResponse "Недостаточно прав или user_id не совпадает с пользователем токена"
+// @Failure 413 {object} api.ErrorResponse "Тело запроса превышает допустимый размер"
+// @Failure 429 {object} api.ErrorResponse "Превышен лимит запросов"
 // @Failure 500 {object} api.ErrorResponse "Внутренняя ошибка сервера"
 // @Router /subscriptions [post]
 func (api *API) CreateSubscriptionHandler(w http.ResponseWriter, r *http.Request) {
@@ -33,43 +41,52 @@ func (api *API) CreateSubscriptionHandler(w http.ResponseWriter, r *http.Request
 		EndDate     *string `json:"end_date"`
 	}
 
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		logger.Warn("Ошибка: не удалось прочитать тело запроса: %v", err)
-		writeJSON(w, http.StatusBadRequest, map[string]any{"error": "Некорректно оформлено тело запроса"})
+	if !decodeJSON(w, r, &req) {
 		return
 	}
 
-	uid, err := uuid.Parse(req.UserID)
-	if err != nil {
-		logger.Warn("Ошибка: некорректный формат uuid: %v", err)
-		writeJSON(w, http.StatusBadRequest, map[string]any{"error": "Указан некорректный формат идентификатора пользователя"})
-		return
+	var uid uuid.UUID
+	if bound := principalFromContext(r.Context()).boundUserID(); bound != nil {
+		if strings.TrimSpace(req.UserID) != "" && req.UserID != bound.String() {
+			logger.WarnContext(r.Context(), "Ошибка: пользователь %s пытается создать подписку для пользователя %s", bound, req.UserID)
+			writeJSON(w, http.StatusForbidden, map[string]any{"error": "Нельзя создать подписку для другого пользователя"})
+			return
+		}
+		uid = *bound
+	} else {
+		parsed, err := uuid.Parse(req.UserID)
+		if err != nil {
+			logger.WarnContext(r.Context(), "Ошибка: некорректный формат uuid: %v", err)
+			writeJSON(w, http.StatusBadRequest, map[string]any{"error": "Указан некорректный формат идентификатора пользователя"})
+			return
+		}
+		uid = parsed
 	}
 
 	serviceName := strings.TrimSpace(req.ServiceName)
 	if serviceName == "" {
-		logger.Warn("Ошибка: не указан сервис подписки")
+		logger.WarnContext(r.Context(), "Ошибка: не указан сервис подписки")
 		writeJSON(w, http.StatusBadRequest, map[string]any{"error": "Не указан сервис подписки"})
 		return
 	}
 
 	reSN := regexp.MustCompile(`^[A-Za-z0-9 ]+$`)
 	if !reSN.MatchString(serviceName) {
-		logger.Warn("Ошибка: в названии сервиса используются недопустимые символы")
+		logger.WarnContext(r.Context(), "Ошибка: в названии сервиса используются недопустимые символы")
 		writeJSON(w, http.StatusBadRequest, map[string]any{"error": "Недопустимое название сервиса: используйте только буквы, цифры и пробелы"})
 		return
 	}
 
 	validPrices := map[int]bool{50: true, 100: true, 200: true}
 	if !validPrices[req.Price] {
-		logger.Warn("Ошибка: выбран несуществующий уровень подписки")
+		logger.WarnContext(r.Context(), "Ошибка: выбран несуществующий уровень подписки")
 		writeJSON(w, http.StatusBadRequest, map[string]any{"error": "Выберите допустимый уровень подписки: Базовый (50), Продвинутый (100), Премиум (200)"})
 		return
 	}
 
 	start, err := time.Parse("01-2006", req.StartDate)
 	if err != nil {
-		logger.Warn("Ошибка: некорректный формат даты начала подписки")
+		logger.WarnContext(r.Context(), "Ошибка: некорректный формат даты начала подписки")
 		writeJSON(w, http.StatusBadRequest, map[string]any{"error": "Неверный формат даты начала действия подписки (используйте месяц-год)"})
 		return
 	}
@@ -78,13 +95,13 @@ func (api *API) CreateSubscriptionHandler(w http.ResponseWriter, r *http.Request
 	if req.EndDate != nil && *req.EndDate != "" {
 		endParsed, err := time.Parse("01-2006", *req.EndDate)
 		if err != nil {
-			logger.Warn("Ошибка: некорректный формат даты конца подписки")
+			logger.WarnContext(r.Context(), "Ошибка: некорректный формат даты конца подписки")
 			writeJSON(w, http.StatusBadRequest, map[string]any{"error": "Неверный формат даты окончания действия подписки (используйте месяц-год)"})
 			return
 		}
 
 		if endParsed.Before(start) {
-			logger.Warn("Ошибка: дата окончания подписки раньше даты начала")
+			logger.WarnContext(r.Context(), "Ошибка: дата окончания подписки раньше даты начала")
 			writeJSON(w, http.StatusBadRequest, map[string]any{"error": "Дата окончания действия подписки не может быть раньше даты ее начала действия"})
 			return
 		}
@@ -103,23 +120,26 @@ func (api *API) CreateSubscriptionHandler(w http.ResponseWriter, r *http.Request
 	if err != nil {
 		switch {
 		case errors.Is(err, database.ErrSubIsExist):
-			logger.Warn("Ошибка: подписка уже существует")
+			logger.WarnContext(r.Context(), "Ошибка: подписка уже существует")
 			writeJSON(w, http.StatusConflict, map[string]any{"error": "Активная подписка на выбранный сервис уже существует"})
 			return
 
 		case errors.Is(err, database.ErrSubOverlapExist):
-			logger.Warn("Ошибка: добавляемая подписка пересекается с другой")
+			logger.WarnContext(r.Context(), "Ошибка: добавляемая подписка пересекается с другой")
 			writeJSON(w, http.StatusConflict, map[string]any{"error": "Период действия добавляемой подписки пересекается с существующей подпиской"})
 			return
 
 		default:
-			logger.Error("Ошибка: не удалось создать подписку %v", err)
+			logger.ErrorContext(r.Context(), "Ошибка: не удалось создать подписку %v", err)
 			writeJSON(w, http.StatusInternalServerError, map[string]any{"error": "Не удалось создать подписку. Повторите попытку позже"})
 			return
 		}
 	}
 
 	writeJSON(w, http.StatusCreated, map[string]any{"message": "Подписка успешно создана"})
-	logger.Info("Создана подписка для пользователя %s на сервис %s", uid, serviceName)
+	recordSubscriptionOperation("create")
+	logger.InfoContext(r.Context(), "Создана подписка для пользователя %s на сервис %s", uid, serviceName)
+
+	api.checkBudgets(context.WithoutCancel(r.Context()), uid)
 
 }
@@ -0,0 +1,127 @@
+package api
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+
+	"github.com/Halturshik/EM-test-task/GO/database"
+	"github.com/Halturshik/EM-test-task/GO/logger"
+)
+
+// @Summary Зарегистрировать вебхук
+// @Description Регистрирует адрес, на который будут отправляться события об изменении подписок. Если секрет не указан, он будет сгенерирован и возвращен один раз в ответе
+// @Tags webhooks
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Param body body api.CreateWebhookRequest true "Параметры вебхука"
+// @Success 201 {object} api.CreateWebhookResponse "Вебхук зарегистрирован"
+// @Failure 400 {object} api.ErrorResponse "Некорректные данные запроса"
+// @Failure 401 {object} api.ErrorResponse "Не указаны или недействительны учетные данные"
+// @Failure 403 {object} api.ErrorResponse "Недостаточно прав"
+// @Failure 413 {object} api.ErrorResponse "Тело запроса превышает допустимый размер"
+// @Failure 429 {object} api.ErrorResponse "Превышен лимит запросов"
+// @Failure 500 {object} api.ErrorResponse "Внутренняя ошибка сервера"
+// @Router /webhooks [post]
+func (api *API) CreateWebhookHandler(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		URL        string   `json:"url"`
+		Secret     string   `json:"secret"`
+		EventTypes []string `json:"event_types"`
+		Enabled    *bool    `json:"enabled"`
+	}
+
+	if !decodeJSON(w, r, &req) {
+		return
+	}
+
+	webhookURL := strings.TrimSpace(req.URL)
+	if !validWebhookURL(webhookURL) {
+		logger.WarnContext(r.Context(), "Ошибка: некорректный адрес вебхука")
+		writeJSON(w, http.StatusBadRequest, map[string]any{"error": "Укажите корректный адрес вебхука (http или https)"})
+		return
+	}
+
+	if err := checkWebhookURLHost(r.Context(), webhookURL); err != nil {
+		logger.WarnContext(r.Context(), "Ошибка: недопустимый адрес вебхука: %v", err)
+		writeJSON(w, http.StatusBadRequest, map[string]any{"error": "Адрес вебхука должен указывать на публичный узел, доступный из интернета"})
+		return
+	}
+
+	if bad, ok := validWebhookEventTypes(req.EventTypes); !ok {
+		logger.WarnContext(r.Context(), "Ошибка: неизвестный тип события %q", bad)
+		writeJSON(w, http.StatusBadRequest, map[string]any{"error": "Неизвестный тип события: " + bad})
+		return
+	}
+
+	secret := req.Secret
+	if secret == "" {
+		generated, err := generateWebhookSecret()
+		if err != nil {
+			logger.ErrorContext(r.Context(), "Ошибка генерации секрета вебхука: %v", err)
+			writeJSON(w, http.StatusInternalServerError, map[string]any{"error": "Не удалось зарегистрировать вебхук. Повторите попытку позже"})
+			return
+		}
+		secret = generated
+	} else if len(secret) < 16 {
+		logger.WarnContext(r.Context(), "Ошибка: слишком короткий секрет вебхука")
+		writeJSON(w, http.StatusBadRequest, map[string]any{"error": "Секрет вебхука должен содержать не менее 16 символов"})
+		return
+	}
+
+	webhook := &database.Webhook{
+		URL:        webhookURL,
+		Secret:     secret,
+		EventTypes: req.EventTypes,
+		Enabled:    req.Enabled == nil || *req.Enabled,
+	}
+	if webhook.EventTypes == nil {
+		webhook.EventTypes = []string{}
+	}
+
+	if err := api.Store.CreateWebhook(r.Context(), webhook); err != nil {
+		logger.ErrorContext(r.Context(), "Ошибка: не удалось зарегистрировать вебхук: %v", err)
+		writeJSON(w, http.StatusInternalServerError, map[string]any{"error": "Не удалось зарегистрировать вебхук. Повторите попытку позже"})
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, map[string]any{
+		"id":          webhook.ID,
+		"url":         webhook.URL,
+		"secret":      webhook.Secret,
+		"event_types": webhook.EventTypes,
+		"enabled":     webhook.Enabled,
+		"created_at":  webhook.CreatedAt,
+	})
+	logger.InfoContext(r.Context(), "Зарегистрирован вебхук %d на адрес %s", webhook.ID, webhook.URL)
+}
+
+func validWebhookURL(raw string) bool {
+	u, err := url.Parse(raw)
+	if err != nil {
+		return false
+	}
+	return (u.Scheme == "http" || u.Scheme == "https") && u.Host != ""
+}
+
+func validWebhookEventTypes(eventTypes []string) (string, bool) {
+	for _, t := range eventTypes {
+		if !slices.Contains(database.WebhookEventTypes, t) {
+			return t, false
+		}
+	}
+	return "", true
+}
+
+func generateWebhookSecret() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
@@ -0,0 +1,48 @@
+package api
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/Halturshik/EM-test-task/GO/logger"
+)
+
+// @Summary Получить недоставленные вебхуки
+// @Description Возвращает доставки вебхуков, для которых исчерпаны все попытки отправки
+// @Tags admin
+// @Produce json
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Param page query int false "Номер страницы для пагинации" default(1)
+// @Success 200 {array} api.WebhookDeliveryResponse "Недоставленные вебхуки"
+// @Failure 401 {object} api.ErrorResponse "Не указаны или недействительны учетные данные"
+// @Failure 403 {object} api.ErrorResponse "Недостаточно прав"
+// @Failure 429 {object} api.ErrorResponse "Превышен лимит запросов"
+// @Failure 500 {object} api.ErrorResponse "Внутренняя ошибка сервера"
+// @Router /admin/webhooks/dead-letters [get]
+func (api *API) GetDeadLettersHandler(w http.ResponseWriter, r *http.Request) {
+	page := 1
+	if p := r.URL.Query().Get("page"); p != "" {
+		if parsed, err := strconv.Atoi(p); err == nil && parsed > 0 {
+			page = parsed
+		}
+	}
+
+	limit := 20
+	offset := (page - 1) * limit
+
+	deliveries, err := api.Store.GetDeadLetters(r.Context(), limit, offset)
+	if err != nil {
+		logger.ErrorContext(r.Context(), "Ошибка: не удалось получить недоставленные вебхуки: %v", err)
+		writeJSON(w, http.StatusInternalServerError, map[string]any{"error": "Не удалось получить недоставленные вебхуки. Повторите попытку позже"})
+		return
+	}
+
+	if len(deliveries) == 0 {
+		writeJSON(w, http.StatusOK, map[string]any{"message": "Недоставленных вебхуков нет"})
+		return
+	}
+
+	writeJSON(w, http.StatusOK, deliveries)
+	logger.InfoContext(r.Context(), "Выдано недоставленных вебхуков: page=%d count=%d", page, len(deliveries))
+}
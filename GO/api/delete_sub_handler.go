@@ -1,7 +1,6 @@
 package api
 
 import (
-	"encoding/json"
 	"errors"
 	"net/http"
 	"regexp"
@@ -19,12 +18,20 @@ import (
 // @Tags subscriptions
 // @Accept json
 // @Produce json
+// @Security ApiKeyAuth
+// @Security BearerAuth
 // @Param user_id path string true "UUID пользователя"
 // @Param service_name path string true "Название сервиса"
+// @Param If-Match header string false "ETag подписки, полученный из GET"
 // @Param body body api.DeleteSubRequest true "Дата начала подписки для удаления"
 // @Success 200 {object} api.DeleteSubResponse "Подписка успешно удалена"
 // @Failure 400 {object} api.ErrorResponse "Некорректные данные запроса"
 // @Failure 404 {object} api.ErrorResponse "Подписка не найдена"
+// @Failure 412 {object} api.ErrorResponse "Подписка была изменена другим запросом"
+// @Failure 401 {object} api.ErrorResponse "Не указаны или недействительны учетные данные"
+// @Failure 403 {object} api.ErrorResponse "Недостаточно прав"
+// @Failure 413 {object} api.ErrorResponse "Тело запроса превышает допустимый размер"
+// @Failure 429 {object} api.ErrorResponse "Превышен лимит запросов"
 // @Failure 500 {object} api.ErrorResponse "Внутренняя ошибка сервера"
 // @Router /users/{user_id}/subscriptions/{service_name} [delete]
 func (api *API) DeleteSubscriptionHandler(w http.ResponseWriter, r *http.Request) {
@@ -32,61 +39,72 @@ func (api *API) DeleteSubscriptionHandler(w http.ResponseWriter, r *http.Request
 	serviceName := strings.TrimSpace(chi.URLParam(r, "service_name"))
 
 	if strings.TrimSpace(userIDStr) == "" || serviceName == "" {
-		logger.Warn("Ошибка: не указан uuid пользователя или название сервиса")
+		logger.WarnContext(r.Context(), "Ошибка: не указан uuid пользователя или название сервиса")
 		writeJSON(w, http.StatusBadRequest, map[string]any{"error": "Не указан идентификатор пользователя или название сервиса подписки"})
 		return
 	}
 
 	userID, err := uuid.Parse(userIDStr)
 	if err != nil {
-		logger.Warn("Ошибка: некорректный формат uuid: %v", err)
+		logger.WarnContext(r.Context(), "Ошибка: некорректный формат uuid: %v", err)
 		writeJSON(w, http.StatusBadRequest, map[string]any{"error": "Некорректный формат идентификатора пользователя"})
 		return
 	}
 
 	reSN := regexp.MustCompile(`^[A-Za-z0-9 ]+$`)
 	if !reSN.MatchString(serviceName) {
-		logger.Warn("Ошибка: в названии сервиса используются недопустимые символы")
+		logger.WarnContext(r.Context(), "Ошибка: в названии сервиса используются недопустимые символы")
 		writeJSON(w, http.StatusBadRequest, map[string]any{"error": "Недопустимое название сервиса: используйте только буквы, цифры и пробелы"})
 		return
 	}
 
+	expectedVersion, err := parseIfMatch(r)
+	if err != nil {
+		logger.WarnContext(r.Context(), "Ошибка: некорректный заголовок If-Match")
+		writeJSON(w, http.StatusBadRequest, map[string]any{"error": "Некорректный заголовок If-Match"})
+		return
+	}
+
 	type deleteReq struct {
 		StartDate string `json:"start_date"`
 	}
 
 	var req deleteReq
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		logger.Warn("Ошибка: не удалось прочитать тело запроса: %v", err)
-		writeJSON(w, http.StatusBadRequest, map[string]any{"error": "Некорректно оформлено тело запроса"})
+	if !decodeJSON(w, r, &req) {
 		return
 	}
 
 	if strings.TrimSpace(req.StartDate) == "" {
-		logger.Warn("Ошибка: не указана дата начала подписки для удаления")
+		logger.WarnContext(r.Context(), "Ошибка: не указана дата начала подписки для удаления")
 		writeJSON(w, http.StatusBadRequest, map[string]any{"error": "Не указана дата начала действия подписки, которую вы хотите удалить"})
 		return
 	}
 
 	startDate, err := time.Parse("01-2006", req.StartDate)
 	if err != nil {
-		logger.Warn("Ошибка: некорректный формат даты начала подписки для удаления")
+		logger.WarnContext(r.Context(), "Ошибка: некорректный формат даты начала подписки для удаления")
 		writeJSON(w, http.StatusBadRequest, map[string]any{"error": "Неверный формат даты начала действия подписки (используйте месяц-год)"})
 		return
 	}
 
-	err = api.Store.DeleteSubscription(r.Context(), userID, serviceName, startDate)
+	err = api.Store.DeleteSubscription(r.Context(), userID, serviceName, startDate, expectedVersion)
 	if err != nil {
 		if errors.Is(err, database.ErrSubNotFound) {
-			logger.Warn("Ошибка: подписка не найдена")
+			logger.WarnContext(r.Context(), "Ошибка: подписка не найдена")
 			writeJSON(w, http.StatusNotFound, map[string]any{"error": "Подписка не найдена"})
 			return
 		}
-		logger.Error("Ошибка при удалении подписки: %v", err)
+		if errors.Is(err, database.ErrSubVersionMismatch) {
+			logger.WarnContext(r.Context(), "Ошибка: версия подписки не совпадает с If-Match")
+			writeJSON(w, http.StatusPreconditionFailed, map[string]any{"error": "Подписка была изменена другим запросом. Получите актуальную версию и повторите попытку"})
+			return
+		}
+		logger.ErrorContext(r.Context(), "Ошибка при удалении подписки: %v", err)
 		writeJSON(w, http.StatusInternalServerError, map[string]any{"error": "Ошибка при удалении подписки. Повторите попытку позже"})
 		return
 	}
 
 	writeJSON(w, http.StatusOK, map[string]any{"message": "Подписка успешно удалена"})
-	logger.Info("Удалена подписка пользователя %s на сервис %s с датой начала %s", userID, serviceName, req.StartDate)
+	recordSubscriptionOperation("delete")
+	logger.InfoContext(r.Context(), "Удалена подписка пользователя %s на сервис %s с датой начала %s", userID, serviceName, req.StartDate)
 }
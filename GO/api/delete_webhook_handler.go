@@ -0,0 +1,49 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/Halturshik/EM-test-task/GO/database"
+	"github.com/Halturshik/EM-test-task/GO/logger"
+	"github.com/go-chi/chi/v5"
+)
+
+// @Summary Удалить вебхук
+// @Description Удаляет вебхук вместе с историей его доставок
+// @Tags webhooks
+// @Produce json
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Param webhook_id path int true "Идентификатор вебхука"
+// @Success 200 {object} api.DeleteWebhookResponse "Вебхук удален"
+// @Failure 400 {object} api.ErrorResponse "Некорректный идентификатор вебхука"
+// @Failure 404 {object} api.ErrorResponse "Вебхук не найден"
+// @Failure 401 {object} api.ErrorResponse "Не указаны или недействительны учетные данные"
+// @Failure 403 {object} api.ErrorResponse "Недостаточно прав"
+// @Failure 429 {object} api.ErrorResponse "Превышен лимит запросов"
+// @Failure 500 {object} api.ErrorResponse "Внутренняя ошибка сервера"
+// @Router /webhooks/{webhook_id} [delete]
+func (api *API) DeleteWebhookHandler(w http.ResponseWriter, r *http.Request) {
+	webhookID, err := strconv.Atoi(chi.URLParam(r, "webhook_id"))
+	if err != nil || webhookID <= 0 {
+		logger.WarnContext(r.Context(), "Ошибка: некорректный идентификатор вебхука")
+		writeJSON(w, http.StatusBadRequest, map[string]any{"error": "Некорректный идентификатор вебхука"})
+		return
+	}
+
+	if err := api.Store.DeleteWebhook(r.Context(), webhookID); err != nil {
+		if errors.Is(err, database.ErrWebhookNotFound) {
+			logger.WarnContext(r.Context(), "Ошибка: вебхук %d не найден", webhookID)
+			writeJSON(w, http.StatusNotFound, map[string]any{"error": "Вебхук не найден"})
+			return
+		}
+		logger.ErrorContext(r.Context(), "Ошибка при удалении вебхука: %v", err)
+		writeJSON(w, http.StatusInternalServerError, map[string]any{"error": "Ошибка при удалении вебхука. Повторите попытку позже"})
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]any{"message": "Вебхук успешно удален"})
+	logger.InfoContext(r.Context(), "Удален вебхук %d", webhookID)
+}
@@ -37,6 +37,12 @@ type UpdateSubRequest struct {
 	NewEndDate *string `json:"new_end_date,omitempty" example:"12-2025"`
 }
 
+type PatchSubRequest struct {
+	Price     *int    `json:"price,omitempty" example:"200"`
+	StartDate *string `json:"start_date,omitempty" example:"01-2026"`
+	EndDate   *string `json:"end_date,omitempty" example:"12-2026"`
+}
+
 type UpdateSubResponse struct {
 	Message string `json:"message" example:"Уровень подписки повышен и уже действует. Дата окончания подписки изменена"`
 }
@@ -48,3 +54,218 @@ type DeleteSubRequest struct {
 type DeleteSubResponse struct {
 	Message string `json:"message" example:"Подписка успешно удалена"`
 }
+
+type RestoreSubRequest struct {
+	StartDate string `json:"start_date" example:"07-2025"`
+}
+
+type RestoreSubResponse struct {
+	Message string `json:"message" example:"Подписка успешно восстановлена"`
+}
+
+type CreateWebhookRequest struct {
+	URL        string   `json:"url" example:"https://billing.example.com/hooks"`
+	Secret     string   `json:"secret,omitempty" example:"c2VjcmV0LXNlY3JldC1zZWNyZXQ="`
+	EventTypes []string `json:"event_types,omitempty" example:"subscription.created,subscription.deleted"`
+	Enabled    *bool    `json:"enabled,omitempty" example:"true"`
+}
+
+type CreateWebhookResponse struct {
+	ID         int      `json:"id" example:"3"`
+	URL        string   `json:"url" example:"https://billing.example.com/hooks"`
+	Secret     string   `json:"secret" example:"c2VjcmV0LXNlY3JldC1zZWNyZXQ="`
+	EventTypes []string `json:"event_types" example:"subscription.created,subscription.deleted"`
+	Enabled    bool     `json:"enabled" example:"true"`
+	CreatedAt  string   `json:"created_at" example:"2025-11-14T11:00:00Z"`
+}
+
+type UpdateWebhookRequest struct {
+	URL        *string   `json:"url,omitempty" example:"https://billing.example.com/hooks/v2"`
+	Secret     *string   `json:"secret,omitempty" example:"bmV3LXNlY3JldC1uZXctc2VjcmV0"`
+	EventTypes *[]string `json:"event_types,omitempty" example:"subscription.upgraded"`
+	Enabled    *bool     `json:"enabled,omitempty" example:"false"`
+}
+
+type WebhookResponse struct {
+	ID         int      `json:"id" example:"3"`
+	URL        string   `json:"url" example:"https://billing.example.com/hooks"`
+	EventTypes []string `json:"event_types" example:"subscription.created,subscription.deleted"`
+	Enabled    bool     `json:"enabled" example:"true"`
+	CreatedAt  string   `json:"created_at" example:"2025-11-14T11:00:00Z"`
+}
+
+type DeleteWebhookResponse struct {
+	Message string `json:"message" example:"Вебхук успешно удален"`
+}
+
+type TestWebhookResponse struct {
+	Message    string `json:"message" example:"Тестовое событие поставлено в очередь на отправку"`
+	DeliveryID int64  `json:"delivery_id" example:"27"`
+}
+
+type ForecastMonthResponse struct {
+	Month     string `json:"month" example:"12-2025"`
+	Committed int    `json:"committed" example:"100"`
+	Projected int    `json:"projected" example:"200"`
+	Total     int    `json:"total" example:"300"`
+}
+
+type ForecastResponse struct {
+	From           string                  `json:"from" example:"12-2025"`
+	To             string                  `json:"to" example:"11-2026"`
+	Months         []ForecastMonthResponse `json:"months"`
+	CommittedTotal int                     `json:"committed_total" example:"600"`
+	ProjectedTotal int                     `json:"projected_total" example:"2400"`
+	Total          int                     `json:"total" example:"3000"`
+}
+
+type ReportMonthResponse struct {
+	Month    string         `json:"month" example:"03-2025"`
+	Services map[string]int `json:"services"`
+	Total    int            `json:"total" example:"900"`
+}
+
+type ReportResponse struct {
+	From          string                `json:"from" example:"01-2025"`
+	To            string                `json:"to" example:"06-2025"`
+	Services      []string              `json:"services" example:"Netflix,Yandex Plus"`
+	Months        []ReportMonthResponse `json:"months"`
+	ServiceTotals map[string]int        `json:"service_totals"`
+	Total         int                   `json:"total" example:"5100"`
+}
+
+type TierCountResponse struct {
+	Price int `json:"price" example:"400"`
+	Count int `json:"count" example:"12"`
+}
+
+type ServiceMetricsResponse struct {
+	ServiceName string              `json:"service_name" example:"Yandex Plus"`
+	MRR         int                 `json:"mrr" example:"4800"`
+	Active      int                 `json:"active" example:"12"`
+	New         int                 `json:"new" example:"3"`
+	Cancelled   int                 `json:"cancelled" example:"1"`
+	Upgraded    int                 `json:"upgraded" example:"2"`
+	Downgraded  int                 `json:"downgraded" example:"0"`
+	Tiers       []TierCountResponse `json:"tiers"`
+}
+
+type AnalyticsMonthResponse struct {
+	Month      string                   `json:"month" example:"03-2025"`
+	Cached     bool                     `json:"cached" example:"true"`
+	MRR        int                      `json:"mrr" example:"4800"`
+	Active     int                      `json:"active" example:"12"`
+	New        int                      `json:"new" example:"3"`
+	Cancelled  int                      `json:"cancelled" example:"1"`
+	Upgraded   int                      `json:"upgraded" example:"2"`
+	Downgraded int                      `json:"downgraded" example:"0"`
+	Services   []ServiceMetricsResponse `json:"services"`
+}
+
+type AnalyticsResponse struct {
+	From   string                   `json:"from" example:"01-2025"`
+	To     string                   `json:"to" example:"06-2025"`
+	Months []AnalyticsMonthResponse `json:"months"`
+}
+
+type CohortResponse struct {
+	Cohort      string              `json:"cohort" example:"01-2025"`
+	ServiceName string              `json:"service_name" example:"Yandex Plus"`
+	Size        int                 `json:"size" example:"40"`
+	Retention   map[string]*float64 `json:"retention"`
+}
+
+type CreateAPIKeyRequest struct {
+	Name   string   `json:"name" example:"billing-service"`
+	Scopes []string `json:"scopes" example:"subscriptions:read,subscriptions:write"`
+}
+
+type CreateAPIKeyResponse struct {
+	ID        int      `json:"id" example:"2"`
+	Name      string   `json:"name" example:"billing-service"`
+	Key       string   `json:"key" example:"sk_3f1c9a2b7d4e5f60718293a4b5c6d7e8f9a0b1c2d3e4f5a6"`
+	Prefix    string   `json:"prefix" example:"sk_3f1c9a2b"`
+	Scopes    []string `json:"scopes" example:"subscriptions:read,subscriptions:write"`
+	CreatedAt string   `json:"created_at" example:"2025-11-24T10:00:00Z"`
+}
+
+type APIKeyResponse struct {
+	ID         int      `json:"id" example:"2"`
+	Name       string   `json:"name" example:"billing-service"`
+	Prefix     string   `json:"prefix" example:"sk_3f1c9a2b"`
+	Scopes     []string `json:"scopes" example:"subscriptions:read"`
+	CreatedAt  string   `json:"created_at" example:"2025-11-24T10:00:00Z"`
+	LastUsedAt *string  `json:"last_used_at,omitempty" example:"2025-11-25T08:30:00Z"`
+	RevokedAt  *string  `json:"revoked_at,omitempty"`
+}
+
+type RevokeAPIKeyResponse struct {
+	Message string `json:"message" example:"Ключ отозван"`
+}
+
+type HealthResponse struct {
+	Status        string `json:"status" example:"ok"`
+	Version       string `json:"version" example:"1.4.0"`
+	Uptime        string `json:"uptime" example:"3h12m5s"`
+	UptimeSeconds int64  `json:"uptime_seconds" example:"11525"`
+}
+
+type ReadinessResponse struct {
+	Status        string         `json:"status" example:"ok"`
+	Version       string         `json:"version" example:"1.4.0"`
+	Uptime        string         `json:"uptime" example:"3h12m5s"`
+	UptimeSeconds int64          `json:"uptime_seconds" example:"11525"`
+	Checks        map[string]any `json:"checks"`
+}
+
+type SetBudgetRequest struct {
+	MonthlyLimit int    `json:"monthly_limit" example:"300"`
+	ServiceName  string `json:"service_name,omitempty" example:"Yandex Plus"`
+}
+
+type SetBudgetResponse struct {
+	Message string `json:"message" example:"Бюджет успешно установлен"`
+}
+
+type BudgetStatusResponse struct {
+	ServiceName          string `json:"service_name,omitempty" example:"Yandex Plus"`
+	MonthlyLimit         int    `json:"monthly_limit" example:"300"`
+	ProjectedMonthlyCost int    `json:"projected_monthly_cost" example:"350"`
+	Remaining            int    `json:"remaining" example:"-50"`
+	Exceeded             bool   `json:"exceeded" example:"true"`
+}
+
+type SetContactRequest struct {
+	Email string `json:"email" example:"user@example.com"`
+}
+
+type SetContactResponse struct {
+	Message string `json:"message" example:"Адрес для уведомлений сохранен"`
+}
+
+type WebhookDeliveryResponse struct {
+	ID         int64  `json:"id" example:"15"`
+	WebhookID  int    `json:"webhook_id" example:"3"`
+	URL        string `json:"url" example:"https://billing.example.com/hooks"`
+	EventType  string `json:"event_type" example:"subscription.upgraded"`
+	Payload    any    `json:"payload"`
+	Status     string `json:"status" example:"dead"`
+	Attempts   int    `json:"attempts" example:"8"`
+	LastStatus int    `json:"last_status_code,omitempty" example:"503"`
+	LastError  string `json:"last_error,omitempty" example:"получатель ответил статусом 503"`
+	CreatedAt  string `json:"created_at" example:"2025-11-14T11:00:00Z"`
+	FinishedAt string `json:"finished_at,omitempty" example:"2025-11-15T05:00:00Z"`
+}
+
+type SubEventResponse struct {
+	ID             int64  `json:"id" example:"42"`
+	SubscriptionID int    `json:"subscription_id" example:"7"`
+	UserID         string `json:"user_id" example:"60601fee-2bf1-4721-ae6f-7636e79a0cba"`
+	ServiceName    string `json:"service_name" example:"Yandex Plus"`
+	EventType      string `json:"event_type" example:"upgrade"`
+	Actor          string `json:"actor" example:"anonymous"`
+	RequestID      string `json:"request_id,omitempty" example:"5f1c2a8e-2a41-4c1b-9a43-1f0c6f3b8f7d"`
+	Before         any    `json:"before,omitempty"`
+	After          any    `json:"after,omitempty"`
+	CreatedAt      string `json:"created_at" example:"2025-11-10T10:00:00Z"`
+}
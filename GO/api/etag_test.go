@@ -0,0 +1,67 @@
+package api
+
+import (
+	"errors"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestFormatETag(t *testing.T) {
+	if got := formatETag(42); got != `"42"` {
+		t.Fatalf("formatETag(42) = %s, ожидалось \"42\"", got)
+	}
+}
+
+func TestParseIfMatch(t *testing.T) {
+	tests := []struct {
+		name    string
+		header  string
+		want    *int64
+		wantErr bool
+	}{
+		{name: "без заголовка"},
+		{name: "звездочка", header: "*"},
+		{name: "версия", header: `"42"`, want: ptr(int64(42))},
+		{name: "пробелы вокруг", header: `  "7"  `, want: ptr(int64(7))},
+		{name: "результат formatETag", header: formatETag(1001), want: ptr(int64(1001))},
+		{name: "слабый ETag", header: `W/"42"`, wantErr: true},
+		{name: "без кавычек", header: "42", wantErr: true},
+		{name: "одна кавычка", header: `"`, wantErr: true},
+		{name: "не число", header: `"abc"`, wantErr: true},
+		{name: "несколько значений", header: `"1", "2"`, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest("PUT", "/", nil)
+			if tt.header != "" {
+				r.Header.Set("If-Match", tt.header)
+			}
+
+			got, err := parseIfMatch(r)
+			if tt.wantErr {
+				if !errors.Is(err, errInvalidIfMatch) {
+					t.Fatalf("ожидалась ошибка errInvalidIfMatch, получено %v", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("неожиданная ошибка: %v", err)
+			}
+			if (got == nil) != (tt.want == nil) || (got != nil && *got != *tt.want) {
+				t.Fatalf("parseIfMatch(%q) = %v, ожидалось %v", tt.header, deref(got), deref(tt.want))
+			}
+		})
+	}
+}
+
+func ptr[T any](v T) *T {
+	return &v
+}
+
+func deref(v *int64) any {
+	if v == nil {
+		return nil
+	}
+	return *v
+}
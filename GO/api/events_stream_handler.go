@@ -0,0 +1,178 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/Halturshik/EM-test-task/GO/database"
+	"github.com/Halturshik/EM-test-task/GO/logger"
+	"github.com/google/uuid"
+)
+
+// @Summary Поток изменений подписок
+// @Description Отправляет события об изменении подписок в формате Server-Sent Events по мере их фиксации. При авторизации по JWT поток ограничен событиями пользователя из токена. Поддерживает возобновление с заголовка Last-Event-ID (или параметра last_event_id). Позиция в потоке (id) — снимок транзакций PostgreSQL вида xmin:xmax:xip и передается после каждой пачки событий; при обрыве посередине пачки ее события будут отправлены повторно, поэтому повторы следует отбрасывать по полю id в данных события
+// @Tags events
+// @Produce text/event-stream
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Param user_id query string false "UUID пользователя для фильтрации событий"
+// @Param Last-Event-ID header string false "Идентификатор последнего полученного события"
+// @Param last_event_id query string false "Идентификатор последнего полученного события"
+// @Success 200 {string} string "Поток событий"
+// @Failure 400 {object} api.ErrorResponse "Некорректные параметры запроса"
+// @Failure 401 {object} api.ErrorResponse "Не указаны или недействительны учетные данные"
+// @Failure 403 {object} api.ErrorResponse "Недостаточно прав"
+// @Failure 429 {object} api.ErrorResponse "Превышен лимит запросов"
+// @Failure 500 {object} api.ErrorResponse "Внутренняя ошибка сервера"
+// @Router /events/stream [get]
+func (api *API) EventsStreamHandler(w http.ResponseWriter, r *http.Request) {
+	var userID *uuid.UUID
+	if raw := strings.TrimSpace(r.URL.Query().Get("user_id")); raw != "" {
+		parsed, err := uuid.Parse(raw)
+		if err != nil {
+			logger.WarnContext(r.Context(), "Ошибка: некорректный формат uuid: %v", err)
+			writeJSON(w, http.StatusBadRequest, map[string]any{"error": "Некорректный формат идентификатора пользователя"})
+			return
+		}
+		userID = &parsed
+	}
+
+	if bound := principalFromContext(r.Context()).boundUserID(); bound != nil {
+		if userID != nil && *userID != *bound {
+			logger.WarnContext(r.Context(), "Ошибка: пользователь %s запрашивает поток событий пользователя %s", bound, userID)
+			writeJSON(w, http.StatusForbidden, map[string]any{"error": "Доступ к данным другого пользователя запрещен"})
+			return
+		}
+		userID = bound
+	}
+
+	lastEventID := strings.TrimSpace(r.Header.Get("Last-Event-ID"))
+	if lastEventID == "" {
+		lastEventID = strings.TrimSpace(r.URL.Query().Get("last_event_id"))
+	}
+
+	var cursor database.EventCursor
+	if lastEventID != "" {
+		parsed, err := database.ParseEventCursor(lastEventID)
+		if err != nil {
+			logger.WarnContext(r.Context(), "Ошибка: некорректный Last-Event-ID %q", lastEventID)
+			writeJSON(w, http.StatusBadRequest, map[string]any{"error": "Некорректный идентификатор последнего события"})
+			return
+		}
+		cursor = parsed
+	} else {
+		current, err := api.Store.GetSubscriptionEventCursor(r.Context())
+		if err != nil {
+			logger.ErrorContext(r.Context(), "Ошибка: не удалось получить текущую позицию потока событий: %v", err)
+			writeJSON(w, http.StatusInternalServerError, map[string]any{"error": "Не удалось открыть поток событий. Повторите попытку позже"})
+			return
+		}
+		cursor = current
+	}
+
+	rc := http.NewResponseController(w)
+	if err := rc.SetWriteDeadline(time.Time{}); err != nil {
+		logger.WarnContext(r.Context(), "Не удалось снять ограничение времени записи для потока событий: %v", err)
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+
+	if _, err := fmt.Fprint(w, "retry: 3000\n\n"); err != nil {
+		return
+	}
+	if err := rc.Flush(); err != nil {
+		logger.ErrorContext(r.Context(), "Ошибка: поток событий не поддерживается: %v", err)
+		return
+	}
+
+	logger.InfoContext(r.Context(), "Открыт поток событий: user=%v last_event_id=%s", userID, cursor)
+
+	poll := time.NewTicker(api.Config.SSEPollInterval)
+	defer poll.Stop()
+	heartbeat := time.NewTicker(15 * time.Second)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			logger.InfoContext(r.Context(), "Поток событий закрыт клиентом: user=%v last_event_id=%s", userID, cursor)
+			return
+
+		case <-heartbeat.C:
+			if _, err := fmt.Fprint(w, ": ping\n\n"); err != nil {
+				return
+			}
+			if err := rc.Flush(); err != nil {
+				return
+			}
+
+		case <-poll.C:
+			next, sent, err := api.writeEventWindow(r, w, cursor, userID)
+			if err != nil {
+				if r.Context().Err() == nil {
+					logger.ErrorContext(r.Context(), "Ошибка: не удалось получить события для потока: %v", err)
+				}
+				continue
+			}
+			cursor = next
+
+			if sent == 0 {
+				continue
+			}
+			// Позиция передается клиенту только после всего окна, поэтому при
+			// обрыве посередине окна оно будет отправлено заново.
+			if _, err := fmt.Fprintf(w, "id: %s\n\n", cursor); err != nil {
+				return
+			}
+			if err := rc.Flush(); err != nil {
+				return
+			}
+		}
+	}
+}
+
+// writeEventWindow отправляет события транзакций, зафиксированных после снимка
+// from, и возвращает снимок, до которого события отправлены.
+func (api *API) writeEventWindow(r *http.Request, w http.ResponseWriter, from database.EventCursor, userID *uuid.UUID) (database.EventCursor, int, error) {
+	const pageSize = 100
+
+	to, err := api.Store.GetSubscriptionEventCursor(r.Context())
+	if err != nil {
+		return from, 0, err
+	}
+
+	sent := 0
+	var after database.EventPosition
+	for {
+		events, err := api.Store.GetSubscriptionEventsBetween(r.Context(), from, to, after, userID, pageSize)
+		if err != nil {
+			return from, sent, err
+		}
+
+		for _, e := range events {
+			data, err := json.Marshal(e)
+			if err != nil {
+				logger.ErrorContext(r.Context(), "Ошибка при формировании JSON события %d: %v", e.ID, err)
+				continue
+			}
+			if _, err := fmt.Fprintf(w, "event: %s\ndata: %s\n\n", e.EventType, data); err != nil {
+				return from, sent, err
+			}
+			eventsStreamLag.Observe(time.Since(e.CreatedAt).Seconds())
+			sent++
+		}
+
+		if len(events) < pageSize {
+			return to, sent, nil
+		}
+		last := events[len(events)-1]
+		after = database.EventPosition{TxID: last.TxID, ID: last.ID}
+	}
+}
@@ -0,0 +1,104 @@
+package api
+
+import (
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Halturshik/EM-test-task/GO/logger"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+)
+
+// @Summary Прогноз расходов на подписки
+// @Description Прогнозирует расходы пользователя на подписки на ближайшие месяцы, начиная со следующего. Учитываются действующие подписки, известные даты окончания и запланированные понижения уровня. committed — расходы по подпискам с известной датой окончания, projected — по бессрочным подпискам при условии, что они не будут отменены
+// @Tags reports
+// @Produce json
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Param user_id path string true "UUID пользователя"
+// @Param months query int false "Количество месяцев прогноза (1-36)" default(12)
+// @Param service_name query string false "Название сервиса"
+// @Success 200 {object} api.ForecastResponse "Прогноз расходов"
+// @Failure 400 {object} api.ErrorResponse "Некорректные данные запроса"
+// @Failure 401 {object} api.ErrorResponse "Не указаны или недействительны учетные данные"
+// @Failure 403 {object} api.ErrorResponse "Недостаточно прав"
+// @Failure 429 {object} api.ErrorResponse "Превышен лимит запросов"
+// @Failure 500 {object} api.ErrorResponse "Внутренняя ошибка сервера"
+// @Router /users/{user_id}/forecast [get]
+func (api *API) GetForecastHandler(w http.ResponseWriter, r *http.Request) {
+	userID, err := uuid.Parse(strings.TrimSpace(chi.URLParam(r, "user_id")))
+	if err != nil {
+		logger.WarnContext(r.Context(), "Ошибка: некорректный формат uuid: %v", err)
+		writeJSON(w, http.StatusBadRequest, map[string]any{"error": "Некорректный формат идентификатора пользователя"})
+		return
+	}
+
+	serviceName := strings.TrimSpace(r.URL.Query().Get("service_name"))
+	if serviceName != "" {
+		reSN := regexp.MustCompile(`^[A-Za-z0-9 ]+$`)
+		if !reSN.MatchString(serviceName) {
+			logger.WarnContext(r.Context(), "Ошибка: в названии сервиса используются недопустимые символы")
+			writeJSON(w, http.StatusBadRequest, map[string]any{"error": "Недопустимое название сервиса: используйте только буквы, цифры и пробелы"})
+			return
+		}
+	}
+
+	months := 12
+	if m := r.URL.Query().Get("months"); m != "" {
+		parsed, err := strconv.Atoi(m)
+		if err != nil || parsed < 1 || parsed > 36 {
+			logger.WarnContext(r.Context(), "Ошибка: некорректное количество месяцев прогноза")
+			writeJSON(w, http.StatusBadRequest, map[string]any{"error": "Количество месяцев прогноза должно быть от 1 до 36"})
+			return
+		}
+		months = parsed
+	}
+
+	now := time.Now()
+	from := time.Date(now.Year(), now.Month()+1, 1, 0, 0, 0, 0, time.UTC)
+
+	forecast, err := api.Store.ForecastSubscriptionCost(r.Context(), userID, serviceName, from, months)
+	if err != nil {
+		logger.ErrorContext(r.Context(), "Ошибка при прогнозе расходов на подписки: %v", err)
+		writeJSON(w, http.StatusInternalServerError, map[string]any{"error": "Ошибка при прогнозе расходов на подписки. Повторите попытку позже"})
+		return
+	}
+
+	type forecastMonth struct {
+		Month     string `json:"month"`
+		Committed int    `json:"committed"`
+		Projected int    `json:"projected"`
+		Total     int    `json:"total"`
+	}
+
+	resp := struct {
+		From           string          `json:"from"`
+		To             string          `json:"to"`
+		Months         []forecastMonth `json:"months"`
+		CommittedTotal int             `json:"committed_total"`
+		ProjectedTotal int             `json:"projected_total"`
+		Total          int             `json:"total"`
+	}{
+		From:   forecast[0].Month.Format("01-2006"),
+		To:     forecast[len(forecast)-1].Month.Format("01-2006"),
+		Months: make([]forecastMonth, 0, len(forecast)),
+	}
+
+	for _, m := range forecast {
+		resp.Months = append(resp.Months, forecastMonth{
+			Month:     m.Month.Format("01-2006"),
+			Committed: m.Committed,
+			Projected: m.Projected,
+			Total:     m.Committed + m.Projected,
+		})
+		resp.CommittedTotal += m.Committed
+		resp.ProjectedTotal += m.Projected
+	}
+	resp.Total = resp.CommittedTotal + resp.ProjectedTotal
+
+	writeJSON(w, http.StatusOK, resp)
+	logger.InfoContext(r.Context(), "Выдан прогноз расходов пользователя %s: service=%s months=%d total=%d", userID, serviceName, months, resp.Total)
+}
@@ -0,0 +1,35 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/Halturshik/EM-test-task/GO/logger"
+)
+
+// @Summary Получить список API-ключей
+// @Description Возвращает все выпущенные API-ключи, включая отозванные. Сами ключи не возвращаются, только их префиксы
+// @Tags admin
+// @Produce json
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Success 200 {array} api.APIKeyResponse "Список ключей"
+// @Failure 401 {object} api.ErrorResponse "Не указаны или недействительны учетные данные"
+// @Failure 403 {object} api.ErrorResponse "Недостаточно прав"
+// @Failure 429 {object} api.ErrorResponse "Превышен лимит запросов"
+// @Failure 500 {object} api.ErrorResponse "Внутренняя ошибка сервера"
+// @Router /admin/api-keys [get]
+func (api *API) GetAPIKeysHandler(w http.ResponseWriter, r *http.Request) {
+	keys, err := api.Store.GetAPIKeys(r.Context())
+	if err != nil {
+		logger.ErrorContext(r.Context(), "Ошибка: не удалось получить список API-ключей: %v", err)
+		writeJSON(w, http.StatusInternalServerError, map[string]any{"error": "Не удалось получить список API-ключей. Повторите попытку позже"})
+		return
+	}
+
+	if len(keys) == 0 {
+		writeJSON(w, http.StatusOK, map[string]any{"message": "API-ключи не выпущены"})
+		return
+	}
+
+	writeJSON(w, http.StatusOK, keys)
+}
@@ -0,0 +1,79 @@
+package api
+
+import (
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/Halturshik/EM-test-task/GO/logger"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+)
+
+// @Summary Получить историю изменений подписки
+// @Description Возвращает журнал изменений подписок пользователя на сервис (создание, изменение уровня, дат, удаление) от новых к старым
+// @Tags subscriptions
+// @Produce json
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Param user_id path string true "UUID пользователя"
+// @Param service_name path string true "Название сервиса"
+// @Param page query int false "Номер страницы для пагинации" default(1)
+// @Success 200 {array} api.SubEventResponse "Журнал изменений"
+// @Failure 400 {object} api.ErrorResponse "Некорректные данные запроса"
+// @Failure 401 {object} api.ErrorResponse "Не указаны или недействительны учетные данные"
+// @Failure 403 {object} api.ErrorResponse "Недостаточно прав"
+// @Failure 429 {object} api.ErrorResponse "Превышен лимит запросов"
+// @Failure 500 {object} api.ErrorResponse "Внутренняя ошибка сервера"
+// @Router /users/{user_id}/subscriptions/{service_name}/events [get]
+func (api *API) GetSubscriptionEventsHandler(w http.ResponseWriter, r *http.Request) {
+	userIDStr := chi.URLParam(r, "user_id")
+	serviceName := strings.TrimSpace(chi.URLParam(r, "service_name"))
+
+	if strings.TrimSpace(userIDStr) == "" || serviceName == "" {
+		logger.WarnContext(r.Context(), "Ошибка: не указан uuid пользователя или название сервиса")
+		writeJSON(w, http.StatusBadRequest, map[string]any{"error": "Не указан идентификатор пользователя или название сервиса подписки"})
+		return
+	}
+
+	userID, err := uuid.Parse(userIDStr)
+	if err != nil {
+		logger.WarnContext(r.Context(), "Ошибка: некорректный формат uuid: %v", err)
+		writeJSON(w, http.StatusBadRequest, map[string]any{"error": "Некорректный формат идентификатора пользователя"})
+		return
+	}
+
+	reSN := regexp.MustCompile(`^[A-Za-z0-9 ]+$`)
+	if !reSN.MatchString(serviceName) {
+		logger.WarnContext(r.Context(), "Ошибка: в названии сервиса используются недопустимые символы")
+		writeJSON(w, http.StatusBadRequest, map[string]any{"error": "Недопустимое название сервиса: используйте только буквы, цифры и пробелы"})
+		return
+	}
+
+	page := 1
+	if p := r.URL.Query().Get("page"); p != "" {
+		if parsed, err := strconv.Atoi(p); err == nil && parsed > 0 {
+			page = parsed
+		}
+	}
+
+	limit := 20
+	offset := (page - 1) * limit
+
+	events, err := api.Store.GetSubscriptionEvents(r.Context(), userID, serviceName, limit, offset)
+	if err != nil {
+		logger.ErrorContext(r.Context(), "Ошибка: не удалось получить историю изменений подписки: %v", err)
+		writeJSON(w, http.StatusInternalServerError, map[string]any{"error": "Не удалось получить историю изменений подписки. Повторите попытку позже"})
+		return
+	}
+
+	if len(events) == 0 {
+		logger.InfoContext(r.Context(), "Событий по подписке не найдено")
+		writeJSON(w, http.StatusOK, map[string]any{"message": "Событий по подписке не найдено"})
+		return
+	}
+
+	writeJSON(w, http.StatusOK, events)
+	logger.InfoContext(r.Context(), "Выдана история изменений подписки: user=%s service=%s page=%d count=%d", userID, serviceName, page, len(events))
+}
@@ -5,7 +5,6 @@ import (
 	"regexp"
 	"strconv"
 	"strings"
-	"time"
 
 	"github.com/Halturshik/EM-test-task/GO/logger"
 	"github.com/go-chi/chi/v5"
@@ -16,11 +15,17 @@ import (
 // @Description Возвращает список подписок для указанного user_id. Можно фильтровать по статусу и пагинировать.
 // @Tags subscriptions
 // @Produce json
+// @Security ApiKeyAuth
+// @Security BearerAuth
 // @Param user_id path string true "UUID пользователя"
 // @Param status query string false "Статус подписки" Enums(active, archived) default(active)
 // @Param page query int false "Номер страницы для пагинации" default(1)
 // @Success 200 {array} api.SubResponse "Список подписок"
+// @Header 200 {string} ETag "Версия активной подписки (только для запроса с service_name и status=active, если найдена ровно одна подписка)"
 // @Failure 400 {object} api.ErrorResponse "Некорректный UUID пользователя, service_name, статус или номер страницы"
+// @Failure 401 {object} api.ErrorResponse "Не указаны или недействительны учетные данные"
+// @Failure 403 {object} api.ErrorResponse "Недостаточно прав"
+// @Failure 429 {object} api.ErrorResponse "Превышен лимит запросов"
 // @Failure 500 {object} api.ErrorResponse "Внутренняя ошибка сервера"
 // @Router /users/{user_id}/subscriptions [get]
 // @Router /users/{user_id}/subscriptions/{service_name} [get]
@@ -29,14 +34,14 @@ func (api *API) GetSubscriptionsHandler(w http.ResponseWriter, r *http.Request)
 	serviceName := strings.TrimSpace(chi.URLParam(r, "service_name"))
 
 	if strings.TrimSpace(userIDStr) == "" {
-		logger.Warn("Ошибка: не указан uuid пользователя")
+		logger.WarnContext(r.Context(), "Ошибка: не указан uuid пользователя")
 		writeJSON(w, http.StatusBadRequest, map[string]any{"error": "Не указан идентификатор пользователя"})
 		return
 	}
 
 	userID, err := uuid.Parse(userIDStr)
 	if err != nil {
-		logger.Warn("Ошибка: некорректный формат uuid: %v", err)
+		logger.WarnContext(r.Context(), "Ошибка: некорректный формат uuid: %v", err)
 		writeJSON(w, http.StatusBadRequest, map[string]any{"error": "Некорректный формат идентификатора"})
 		return
 	}
@@ -46,7 +51,7 @@ func (api *API) GetSubscriptionsHandler(w http.ResponseWriter, r *http.Request)
 		status = "active"
 	}
 	if status != "active" && status != "archived" {
-		logger.Warn("Ошибка: некорректный статус подписки")
+		logger.WarnContext(r.Context(), "Ошибка: некорректный статус подписки")
 		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "Некорректный статус подписки"})
 		return
 	}
@@ -54,7 +59,7 @@ func (api *API) GetSubscriptionsHandler(w http.ResponseWriter, r *http.Request)
 	if serviceName != "" {
 		reSN := regexp.MustCompile(`^[A-Za-z0-9 ]+$`)
 		if !reSN.MatchString(serviceName) {
-			logger.Warn("Ошибка: в названии сервиса используются недопустимые символы")
+			logger.WarnContext(r.Context(), "Ошибка: в названии сервиса используются недопустимые символы")
 			writeJSON(w, http.StatusBadRequest, map[string]any{"error": "Недопустимое название сервиса: используйте только буквы, цифры и пробелы"})
 			return
 		}
@@ -72,13 +77,13 @@ func (api *API) GetSubscriptionsHandler(w http.ResponseWriter, r *http.Request)
 
 	subsFromDB, err := api.Store.GetSubscriptions(r.Context(), userID, serviceName, status, limit, offset)
 	if err != nil {
-		logger.Error("Ошибка: не удалось вытащить подписку: %v", err)
+		logger.ErrorContext(r.Context(), "Ошибка: не удалось вытащить подписку: %v", err)
 		writeJSON(w, http.StatusInternalServerError, map[string]any{"error": "Не удалось произвести поиск подписки. Повторите попытку позже"})
 		return
 	}
 
 	if len(subsFromDB) == 0 {
-		logger.Info("Подписок не найдено")
+		logger.InfoContext(r.Context(), "Подписок не найдено")
 		writeJSON(w, http.StatusOK, map[string]any{"message": "Подписок не найдено"})
 		return
 	}
@@ -93,8 +98,7 @@ func (api *API) GetSubscriptionsHandler(w http.ResponseWriter, r *http.Request)
 	resp := make([]subsResponse, 0, len(subsFromDB))
 	for _, s := range subsFromDB {
 		var endStr *string
-		infiniteDate := time.Date(2099, 12, 31, 0, 0, 0, 0, s.EndDate.Location())
-		if !s.EndDate.Equal(infiniteDate) {
+		if s.EndDate != nil {
 			tmp := s.EndDate.Format("01-2006")
 			endStr = &tmp
 		}
@@ -106,6 +110,12 @@ func (api *API) GetSubscriptionsHandler(w http.ResponseWriter, r *http.Request)
 		})
 	}
 
+	// ETag относится к подписке, которую изменяют PUT, PATCH и DELETE по тому же
+	// адресу, — единственной активной подписке пользователя на сервис.
+	if serviceName != "" && status == "active" && page == 1 && len(subsFromDB) == 1 {
+		w.Header().Set("ETag", formatETag(subsFromDB[0].Version))
+	}
+
 	writeJSON(w, http.StatusOK, resp)
-	logger.Info("Выдано подписок: user=%s service=%s status=%s page=%d count=%d", userID, serviceName, status, page, len(subsFromDB))
+	logger.InfoContext(r.Context(), "Выдано подписок: user=%s service=%s status=%s page=%d count=%d", userID, serviceName, status, page, len(subsFromDB))
 }
@@ -0,0 +1,78 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/Halturshik/EM-test-task/GO/database"
+	"github.com/Halturshik/EM-test-task/GO/logger"
+	"github.com/go-chi/chi/v5"
+)
+
+// @Summary Получить список вебхуков
+// @Description Возвращает все зарегистрированные вебхуки (без секретов)
+// @Tags webhooks
+// @Produce json
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Success 200 {array} api.WebhookResponse "Список вебхуков"
+// @Failure 401 {object} api.ErrorResponse "Не указаны или недействительны учетные данные"
+// @Failure 403 {object} api.ErrorResponse "Недостаточно прав"
+// @Failure 429 {object} api.ErrorResponse "Превышен лимит запросов"
+// @Failure 500 {object} api.ErrorResponse "Внутренняя ошибка сервера"
+// @Router /webhooks [get]
+func (api *API) GetWebhooksHandler(w http.ResponseWriter, r *http.Request) {
+	webhooks, err := api.Store.GetWebhooks(r.Context())
+	if err != nil {
+		logger.ErrorContext(r.Context(), "Ошибка: не удалось получить список вебхуков: %v", err)
+		writeJSON(w, http.StatusInternalServerError, map[string]any{"error": "Не удалось получить список вебхуков. Повторите попытку позже"})
+		return
+	}
+
+	if len(webhooks) == 0 {
+		writeJSON(w, http.StatusOK, map[string]any{"message": "Вебхуков не найдено"})
+		return
+	}
+
+	writeJSON(w, http.StatusOK, webhooks)
+	logger.InfoContext(r.Context(), "Выдано вебхуков: %d", len(webhooks))
+}
+
+// @Summary Получить вебхук
+// @Description Возвращает зарегистрированный вебхук по идентификатору (без секрета)
+// @Tags webhooks
+// @Produce json
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Param webhook_id path int true "Идентификатор вебхука"
+// @Success 200 {object} api.WebhookResponse "Вебхук"
+// @Failure 400 {object} api.ErrorResponse "Некорректный идентификатор вебхука"
+// @Failure 404 {object} api.ErrorResponse "Вебхук не найден"
+// @Failure 401 {object} api.ErrorResponse "Не указаны или недействительны учетные данные"
+// @Failure 403 {object} api.ErrorResponse "Недостаточно прав"
+// @Failure 429 {object} api.ErrorResponse "Превышен лимит запросов"
+// @Failure 500 {object} api.ErrorResponse "Внутренняя ошибка сервера"
+// @Router /webhooks/{webhook_id} [get]
+func (api *API) GetWebhookHandler(w http.ResponseWriter, r *http.Request) {
+	webhookID, err := strconv.Atoi(chi.URLParam(r, "webhook_id"))
+	if err != nil || webhookID <= 0 {
+		logger.WarnContext(r.Context(), "Ошибка: некорректный идентификатор вебхука")
+		writeJSON(w, http.StatusBadRequest, map[string]any{"error": "Некорректный идентификатор вебхука"})
+		return
+	}
+
+	webhook, err := api.Store.GetWebhook(r.Context(), webhookID)
+	if err != nil {
+		if errors.Is(err, database.ErrWebhookNotFound) {
+			logger.WarnContext(r.Context(), "Ошибка: вебхук %d не найден", webhookID)
+			writeJSON(w, http.StatusNotFound, map[string]any{"error": "Вебхук не найден"})
+			return
+		}
+		logger.ErrorContext(r.Context(), "Ошибка: не удалось получить вебхук: %v", err)
+		writeJSON(w, http.StatusInternalServerError, map[string]any{"error": "Не удалось получить вебхук. Повторите попытку позже"})
+		return
+	}
+
+	writeJSON(w, http.StatusOK, webhook)
+}
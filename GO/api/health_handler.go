@@ -0,0 +1,85 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/Halturshik/EM-test-task/GO/logger"
+)
+
+var BuildVersion = "dev"
+
+var startedAt = time.Now()
+
+// @Summary Проверка работоспособности процесса
+// @Description Отвечает 200, пока процесс запущен и обрабатывает запросы. Не проверяет зависимости
+// @Tags health
+// @Produce json
+// @Success 200 {object} api.HealthResponse "Процесс работает"
+// @Router /healthz [get]
+func (api *API) HealthzHandler(w http.ResponseWriter, r *http.Request) {
+	uptime := time.Since(startedAt)
+	writeJSON(w, http.StatusOK, map[string]any{
+		"status":         "ok",
+		"version":        BuildVersion,
+		"uptime":         uptime.Round(time.Second).String(),
+		"uptime_seconds": int64(uptime.Seconds()),
+	})
+}
+
+// @Summary Проверка готовности сервиса
+// @Description Проверяет соединение с базой данных, применение всех миграций и работу фоновой синхронизации подписок. Отвечает 503, если хотя бы одна проверка не пройдена
+// @Tags health
+// @Produce json
+// @Success 200 {object} api.ReadinessResponse "Сервис готов принимать запросы"
+// @Failure 503 {object} api.ReadinessResponse "Сервис не готов"
+// @Router /readyz [get]
+func (api *API) ReadyzHandler(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), 2*time.Second)
+	defer cancel()
+
+	ready := true
+	checks := make(map[string]any)
+
+	if err := api.Store.Ping(ctx); err != nil {
+		ready = false
+		checks["database"] = map[string]any{"status": "error", "error": err.Error()}
+	} else {
+		checks["database"] = map[string]any{"status": "ok"}
+	}
+
+	current, expected, err := api.Store.GetMigrationVersions(ctx)
+	switch {
+	case err != nil:
+		ready = false
+		checks["migrations"] = map[string]any{"status": "error", "error": err.Error()}
+	case current != expected:
+		ready = false
+		checks["migrations"] = map[string]any{"status": "error", "current": current, "expected": expected}
+	default:
+		checks["migrations"] = map[string]any{"status": "ok", "current": current, "expected": expected}
+	}
+
+	if MonthlySyncAlive() {
+		checks["monthly_sync"] = map[string]any{"status": "ok"}
+	} else {
+		ready = false
+		checks["monthly_sync"] = map[string]any{"status": "error", "error": "фоновая синхронизация подписок не запущена или не отвечает"}
+	}
+
+	status, code := "ok", http.StatusOK
+	if !ready {
+		status, code = "unavailable", http.StatusServiceUnavailable
+		logger.WarnContext(r.Context(), "Сервис не готов: %v", checks)
+	}
+
+	uptime := time.Since(startedAt)
+	writeJSON(w, code, map[string]any{
+		"status":         status,
+		"version":        BuildVersion,
+		"uptime":         uptime.Round(time.Second).String(),
+		"uptime_seconds": int64(uptime.Seconds()),
+		"checks":         checks,
+	})
+}
@@ -0,0 +1,135 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/Halturshik/EM-test-task/GO/logger"
+)
+
+const idempotencyKeyHeader = "Idempotency-Key"
+
+type captureResponseWriter struct {
+	http.ResponseWriter
+	statusCode int
+	body       bytes.Buffer
+}
+
+func (cw *captureResponseWriter) WriteHeader(code int) {
+	cw.statusCode = code
+	cw.ResponseWriter.WriteHeader(code)
+}
+
+func (cw *captureResponseWriter) Write(b []byte) (int, error) {
+	cw.body.Write(b)
+	return cw.ResponseWriter.Write(b)
+}
+
+func (cw *captureResponseWriter) Unwrap() http.ResponseWriter {
+	return cw.ResponseWriter
+}
+
+func (api *API) IdempotencyMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		key := strings.TrimSpace(r.Header.Get(idempotencyKeyHeader))
+		if key == "" {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		if len(key) > 255 {
+			logger.WarnContext(r.Context(), "Ошибка: слишком длинный ключ идемпотентности")
+			writeJSON(w, http.StatusBadRequest, map[string]any{"error": "Ключ идемпотентности не может быть длиннее 255 символов"})
+			return
+		}
+
+		// Ключи разных клиентов не пересекаются: иначе другой пользователь с тем же
+		// ключом и телом запроса получил бы чужой сохраненный ответ.
+		owner := "anonymous"
+		if p := principalFromContext(r.Context()); p != nil {
+			owner = p.Actor
+		}
+
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			logger.WarnContext(r.Context(), "Ошибка: не удалось прочитать тело запроса: %v", err)
+			var maxErr *http.MaxBytesError
+			if errors.As(err, &maxErr) {
+				writeJSON(w, http.StatusRequestEntityTooLarge, map[string]any{"error": fmt.Sprintf("Тело запроса превышает допустимый размер %d байт", maxErr.Limit)})
+				return
+			}
+			writeJSON(w, http.StatusBadRequest, map[string]any{"error": "Некорректно оформлено тело запроса"})
+			return
+		}
+		r.Body = io.NopCloser(bytes.NewReader(body))
+
+		hash := sha256.New()
+		hash.Write([]byte(r.Method + "\n" + r.URL.Path + "\n"))
+		hash.Write(body)
+		requestHash := hex.EncodeToString(hash.Sum(nil))
+
+		rec, err := api.Store.ReserveIdempotencyKey(r.Context(), owner, key, requestHash, api.Config.IdempotencyTTL)
+		if err != nil {
+			logger.ErrorContext(r.Context(), "Ошибка при резервировании ключа идемпотентности: %v", err)
+			writeJSON(w, http.StatusInternalServerError, map[string]any{"error": "Не удалось обработать запрос. Повторите попытку позже"})
+			return
+		}
+
+		if rec != nil {
+			if rec.RequestHash != requestHash {
+				logger.WarnContext(r.Context(), "Ошибка: ключ идемпотентности %s использован с другим запросом", key)
+				writeJSON(w, http.StatusUnprocessableEntity, map[string]any{"error": "Ключ идемпотентности уже использован для другого запроса"})
+				return
+			}
+
+			if rec.StatusCode == nil {
+				logger.WarnContext(r.Context(), "Ошибка: запрос с ключом идемпотентности %s еще обрабатывается", key)
+				writeJSON(w, http.StatusConflict, map[string]any{"error": "Запрос с этим ключом идемпотентности еще обрабатывается"})
+				return
+			}
+
+			if rec.ContentType != "" {
+				w.Header().Set("Content-Type", rec.ContentType)
+			}
+			w.Header().Set("Idempotent-Replayed", "true")
+			w.WriteHeader(*rec.StatusCode)
+			if _, err := w.Write(rec.Body); err != nil {
+				logger.ErrorContext(r.Context(), "Ошибка при отправке ответа: %v", err)
+			}
+			logger.InfoContext(r.Context(), "Повторно выдан сохраненный ответ для ключа идемпотентности %s", key)
+			return
+		}
+
+		// Ключ освобождается, если ответ не был сохранен: при ошибке 5xx,
+		// сбое сохранения или панике обработчика.
+		saved := false
+		defer func() {
+			if saved {
+				return
+			}
+			if err := api.Store.DeleteIdempotencyKey(context.WithoutCancel(r.Context()), owner, key); err != nil {
+				logger.ErrorContext(r.Context(), "Ошибка при освобождении ключа идемпотентности: %v", err)
+			}
+		}()
+
+		cw := &captureResponseWriter{ResponseWriter: w, statusCode: http.StatusOK}
+		next.ServeHTTP(cw, r)
+
+		if cw.statusCode >= 500 {
+			return
+		}
+
+		if err := api.Store.SaveIdempotencyResponse(context.WithoutCancel(r.Context()), owner, key, cw.statusCode, cw.Header().Get("Content-Type"), cw.body.Bytes()); err != nil {
+			logger.ErrorContext(r.Context(), "Ошибка при сохранении ответа для ключа идемпотентности: %v", err)
+			return
+		}
+		saved = true
+	})
+}
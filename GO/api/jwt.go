@@ -0,0 +1,152 @@
+package api
+
+import (
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+
+	"github.com/Halturshik/EM-test-task/config"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+)
+
+var ErrJWTNotConfigured = errors.New("проверка JWT не настроена")
+
+type JWTVerifier struct {
+	hsSecret []byte
+	rsaKeys  map[string]*rsa.PublicKey
+	issuer   string
+	audience string
+}
+
+type jwtClaims struct {
+	jwt.RegisteredClaims
+	Roles []string `json:"roles"`
+}
+
+type jwtIdentity struct {
+	UserID uuid.UUID
+	Roles  []string
+}
+
+func NewJWTVerifier(cfg *config.Config) (*JWTVerifier, error) {
+	v := &JWTVerifier{
+		rsaKeys:  make(map[string]*rsa.PublicKey),
+		issuer:   cfg.JWTIssuer,
+		audience: cfg.JWTAudience,
+	}
+	if cfg.JWTHS256Secret != "" {
+		v.hsSecret = []byte(cfg.JWTHS256Secret)
+	}
+
+	if cfg.JWTJWKSFile != "" {
+		if err := v.loadJWKS(cfg.JWTJWKSFile); err != nil {
+			return nil, fmt.Errorf("не удалось загрузить JWKS из %s: %w", cfg.JWTJWKSFile, err)
+		}
+	}
+
+	return v, nil
+}
+
+func (v *JWTVerifier) loadJWKS(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	var set struct {
+		Keys []struct {
+			Kty string `json:"kty"`
+			Kid string `json:"kid"`
+			Use string `json:"use"`
+			N   string `json:"n"`
+			E   string `json:"e"`
+		} `json:"keys"`
+	}
+	if err := json.Unmarshal(data, &set); err != nil {
+		return err
+	}
+
+	for _, k := range set.Keys {
+		if k.Kty != "RSA" || (k.Use != "" && k.Use != "sig") {
+			continue
+		}
+		n, err := base64.RawURLEncoding.DecodeString(k.N)
+		if err != nil {
+			return fmt.Errorf("ключ %q: некорректный модуль: %w", k.Kid, err)
+		}
+		e, err := base64.RawURLEncoding.DecodeString(k.E)
+		if err != nil {
+			return fmt.Errorf("ключ %q: некорректная экспонента: %w", k.Kid, err)
+		}
+		v.rsaKeys[k.Kid] = &rsa.PublicKey{
+			N: new(big.Int).SetBytes(n),
+			E: int(new(big.Int).SetBytes(e).Int64()),
+		}
+	}
+
+	if len(v.rsaKeys) == 0 {
+		return errors.New("в файле нет RSA-ключей для подписи")
+	}
+
+	return nil
+}
+
+func (v *JWTVerifier) Verify(raw string) (*jwtIdentity, error) {
+	if v == nil || (v.hsSecret == nil && len(v.rsaKeys) == 0) {
+		return nil, ErrJWTNotConfigured
+	}
+
+	opts := []jwt.ParserOption{
+		jwt.WithValidMethods([]string{"HS256", "RS256"}),
+		jwt.WithExpirationRequired(),
+	}
+	if v.issuer != "" {
+		opts = append(opts, jwt.WithIssuer(v.issuer))
+	}
+	if v.audience != "" {
+		opts = append(opts, jwt.WithAudience(v.audience))
+	}
+
+	var claims jwtClaims
+	if _, err := jwt.ParseWithClaims(raw, &claims, v.keyFunc, opts...); err != nil {
+		return nil, err
+	}
+
+	userID, err := uuid.Parse(claims.Subject)
+	if err != nil {
+		return nil, fmt.Errorf("subject токена не является UUID: %w", err)
+	}
+
+	return &jwtIdentity{
+		UserID: userID,
+		Roles:  claims.Roles,
+	}, nil
+}
+
+func (v *JWTVerifier) keyFunc(token *jwt.Token) (any, error) {
+	switch token.Method.Alg() {
+	case "HS256":
+		if v.hsSecret == nil {
+			return nil, errors.New("токены HS256 не принимаются")
+		}
+		return v.hsSecret, nil
+	case "RS256":
+		kid, _ := token.Header["kid"].(string)
+		if key, ok := v.rsaKeys[kid]; ok {
+			return key, nil
+		}
+		if kid == "" && len(v.rsaKeys) == 1 {
+			for _, key := range v.rsaKeys {
+				return key, nil
+			}
+		}
+		return nil, fmt.Errorf("неизвестный ключ подписи %q", kid)
+	default:
+		return nil, fmt.Errorf("неподдерживаемый алгоритм %s", token.Method.Alg())
+	}
+}
@@ -0,0 +1,196 @@
+package api
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/Halturshik/EM-test-task/config"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+)
+
+const testHSSecret = "0123456789abcdef0123456789abcdef"
+
+func writeTestJWKS(t *testing.T, keys map[string]*rsa.PublicKey) string {
+	t.Helper()
+
+	type jwk struct {
+		Kty string `json:"kty"`
+		Kid string `json:"kid"`
+		Use string `json:"use"`
+		N   string `json:"n"`
+		E   string `json:"e"`
+	}
+	var set struct {
+		Keys []jwk `json:"keys"`
+	}
+	for kid, key := range keys {
+		set.Keys = append(set.Keys, jwk{
+			Kty: "RSA",
+			Kid: kid,
+			Use: "sig",
+			N:   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+			E:   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+		})
+	}
+
+	data, err := json.Marshal(set)
+	if err != nil {
+		t.Fatalf("не удалось сформировать JWKS: %v", err)
+	}
+	path := filepath.Join(t.TempDir(), "jwks.json")
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		t.Fatalf("не удалось записать JWKS: %v", err)
+	}
+	return path
+}
+
+func TestJWTVerifierVerify(t *testing.T) {
+	primary, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("не удалось создать ключ RSA: %v", err)
+	}
+	secondary, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("не удалось создать ключ RSA: %v", err)
+	}
+	unknown, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("не удалось создать ключ RSA: %v", err)
+	}
+
+	verifier, err := NewJWTVerifier(&config.Config{
+		JWTHS256Secret: testHSSecret,
+		JWTJWKSFile:    writeTestJWKS(t, map[string]*rsa.PublicKey{"primary": &primary.PublicKey, "secondary": &secondary.PublicKey}),
+		JWTIssuer:      "https://auth.example.com",
+		JWTAudience:    "subscriptions-api",
+	})
+	if err != nil {
+		t.Fatalf("NewJWTVerifier: %v", err)
+	}
+
+	userID := uuid.New()
+	validClaims := func() jwt.MapClaims {
+		return jwt.MapClaims{
+			"sub":   userID.String(),
+			"iss":   "https://auth.example.com",
+			"aud":   "subscriptions-api",
+			"exp":   time.Now().Add(time.Hour).Unix(),
+			"roles": []string{"support"},
+		}
+	}
+	hs256 := func(claims jwt.MapClaims) string {
+		token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte(testHSSecret))
+		if err != nil {
+			t.Fatalf("не удалось подписать токен: %v", err)
+		}
+		return token
+	}
+	rs256 := func(key *rsa.PrivateKey, kid string, claims jwt.MapClaims) string {
+		token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+		if kid != "" {
+			token.Header["kid"] = kid
+		}
+		signed, err := token.SignedString(key)
+		if err != nil {
+			t.Fatalf("не удалось подписать токен: %v", err)
+		}
+		return signed
+	}
+	with := func(key string, value any) jwt.MapClaims {
+		claims := validClaims()
+		if value == nil {
+			delete(claims, key)
+		} else {
+			claims[key] = value
+		}
+		return claims
+	}
+
+	tests := []struct {
+		name    string
+		token   string
+		wantErr bool
+	}{
+		{name: "HS256", token: hs256(validClaims())},
+		{name: "RS256 с kid", token: rs256(primary, "primary", validClaims())},
+		{name: "RS256 со вторым kid", token: rs256(secondary, "secondary", validClaims())},
+		{name: "RS256 подписан не тем ключом", token: rs256(secondary, "primary", validClaims()), wantErr: true},
+		{name: "RS256 неизвестный kid", token: rs256(unknown, "unknown", validClaims()), wantErr: true},
+		{name: "RS256 без kid при нескольких ключах", token: rs256(primary, "", validClaims()), wantErr: true},
+		{name: "HS256 с чужим секретом", token: func() string {
+			token, _ := jwt.NewWithClaims(jwt.SigningMethodHS256, validClaims()).SignedString([]byte("another-secret-another-secret-00"))
+			return token
+		}(), wantErr: true},
+		{name: "истек", token: hs256(with("exp", time.Now().Add(-time.Minute).Unix())), wantErr: true},
+		{name: "без exp", token: hs256(with("exp", nil)), wantErr: true},
+		{name: "чужая аудитория", token: hs256(with("aud", "other-api")), wantErr: true},
+		{name: "чужой издатель", token: hs256(with("iss", "https://evil.example.com")), wantErr: true},
+		{name: "subject не UUID", token: hs256(with("sub", "admin")), wantErr: true},
+		{name: "алгоритм none", token: func() string {
+			token, _ := jwt.NewWithClaims(jwt.SigningMethodNone, validClaims()).SignedString(jwt.UnsafeAllowNoneSignatureType)
+			return token
+		}(), wantErr: true},
+		{name: "мусор", token: "not-a-jwt", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			identity, err := verifier.Verify(tt.token)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("ожидалась ошибка проверки токена")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Verify: %v", err)
+			}
+			if identity.UserID != userID {
+				t.Errorf("UserID = %s, ожидалось %s", identity.UserID, userID)
+			}
+			if !slices.Equal(identity.Roles, []string{"support"}) {
+				t.Errorf("Roles = %v, ожидалось [support]", identity.Roles)
+			}
+		})
+	}
+}
+
+func TestJWTVerifierNotConfigured(t *testing.T) {
+	verifier, err := NewJWTVerifier(&config.Config{})
+	if err != nil {
+		t.Fatalf("NewJWTVerifier: %v", err)
+	}
+
+	token, _ := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+		"sub": uuid.NewString(),
+		"exp": time.Now().Add(time.Hour).Unix(),
+	}).SignedString([]byte(testHSSecret))
+
+	if _, err := verifier.Verify(token); !errors.Is(err, ErrJWTNotConfigured) {
+		t.Fatalf("Verify = %v, ожидалось %v", err, ErrJWTNotConfigured)
+	}
+}
+
+func TestNewJWTVerifierRejectsBadJWKS(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "jwks.json")
+	if err := os.WriteFile(path, []byte(`{"keys":[]}`), 0o600); err != nil {
+		t.Fatalf("не удалось записать JWKS: %v", err)
+	}
+
+	if _, err := NewJWTVerifier(&config.Config{JWTJWKSFile: path}); err == nil {
+		t.Fatal("ожидалась ошибка для JWKS без ключей")
+	}
+	if _, err := NewJWTVerifier(&config.Config{JWTJWKSFile: filepath.Join(t.TempDir(), "missing.json")}); err == nil {
+		t.Fatal("ожидалась ошибка для отсутствующего файла JWKS")
+	}
+}
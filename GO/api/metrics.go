@@ -0,0 +1,83 @@
+package api
+
+import (
+	"database/sql"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+var (
+	httpRequestsTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "http_requests_total",
+		Help: "Количество обработанных HTTP-запросов",
+	}, []string{"method", "route", "status"})
+
+	httpRequestDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "http_request_duration_seconds",
+		Help:    "Длительность обработки HTTP-запросов",
+		Buckets: prometheus.DefBuckets,
+	}, []string{"method", "route", "status"})
+
+	subscriptionOperationsTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "subscription_operations_total",
+		Help: "Количество успешных операций с подписками по типам",
+	}, []string{"operation"})
+
+	eventsStreamLag = prometheus.NewHistogram(prometheus.HistogramOpts{
+		Name:    "subscription_events_stream_lag_seconds",
+		Help:    "Задержка между началом транзакции, записавшей событие, и его отправкой в поток /events/stream",
+		Buckets: []float64{0.5, 1, 2, 5, 10, 30, 60, 300, 900, 3600},
+	})
+
+	lastPriceSyncTimestamp = prometheus.NewGauge(prometheus.GaugeOpts{
+		Name: "subscription_price_sync_last_success_timestamp_seconds",
+		Help: "Время последней успешной синхронизации цен подписок (unix)",
+	})
+)
+
+func init() {
+	prometheus.MustRegister(httpRequestsTotal, httpRequestDuration, subscriptionOperationsTotal, eventsStreamLag, lastPriceSyncTimestamp)
+}
+
+func RegisterDBMetrics(db *sql.DB) {
+	prometheus.MustRegister(collectors.NewDBStatsCollector(db, "subscriptions"))
+}
+
+func MetricsHandler() http.Handler {
+	return promhttp.Handler()
+}
+
+func observeHTTPRequest(r *http.Request, status int, duration time.Duration) {
+	route := "unmatched"
+	if rctx := chi.RouteContext(r.Context()); rctx != nil && rctx.RoutePattern() != "" {
+		route = rctx.RoutePattern()
+	}
+
+	method := metricMethod(r.Method)
+	httpRequestsTotal.WithLabelValues(method, route, strconv.Itoa(status)).Inc()
+	httpRequestDuration.WithLabelValues(method, route, strconv.Itoa(status)).Observe(duration.Seconds())
+}
+
+// metricMethod сводит нестандартные методы к OTHER, чтобы клиент не мог
+// создавать произвольные значения метки.
+func metricMethod(method string) string {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodPost, http.MethodPut, http.MethodPatch,
+		http.MethodDelete, http.MethodConnect, http.MethodOptions, http.MethodTrace:
+		return method
+	default:
+		return "OTHER"
+	}
+}
+
+func recordSubscriptionOperation(operation string) {
+	if operation != "" {
+		subscriptionOperationsTotal.WithLabelValues(operation).Inc()
+	}
+}
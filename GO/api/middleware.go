@@ -1,9 +1,13 @@
 package api
 
 import (
+	"context"
+	"errors"
+	"log/slog"
 	"net/http"
 	"time"
 
+	"github.com/Halturshik/EM-test-task/GO/database"
 	"github.com/Halturshik/EM-test-task/GO/logger"
 )
 
@@ -17,22 +21,62 @@ func (lrw *logResponseWriter) WriteHeader(code int) {
 	lrw.ResponseWriter.WriteHeader(code)
 }
 
+func (lrw *logResponseWriter) Unwrap() http.ResponseWriter {
+	return lrw.ResponseWriter
+}
+
 func LoggingMiddleware(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		start := time.Now()
 
 		lrw := &logResponseWriter{ResponseWriter: w, statusCode: http.StatusOK}
 
-		logger.Info("→ %s %s", r.Method, r.URL.Path)
+		logger.InfoContext(r.Context(), "→ %s %s", r.Method, r.URL.Path)
 
 		next.ServeHTTP(lrw, r)
 
 		duration := time.Since(start)
+		observeHTTPRequest(r, lrw.statusCode, duration)
 
+		ctx := logger.WithFields(r.Context(), slog.Int("status", lrw.statusCode), slog.Duration("duration", duration))
 		if lrw.statusCode >= 400 {
-			logger.Error("← %s %s завершился с ошибкой %d (заняло %s)", r.Method, r.URL.Path, lrw.statusCode, duration)
+			logger.ErrorContext(ctx, "← %s %s завершился с ошибкой %d (заняло %s)", r.Method, r.URL.Path, lrw.statusCode, duration)
 		} else {
-			logger.Info("← %s %s (заняло %s)", r.Method, r.URL.Path, duration)
+			logger.InfoContext(ctx, "← %s %s (заняло %s)", r.Method, r.URL.Path, duration)
+		}
+	})
+}
+
+func AuditContextMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// Инициатор берется только из проверенных учетных данных в AuthMiddleware,
+		// до аутентификации изменения записываются как anonymous.
+		info := database.AuditInfo{
+			RequestID: requestIDFromContext(r.Context()),
+		}
+
+		next.ServeHTTP(w, r.WithContext(database.WithAuditInfo(r.Context(), info)))
+	})
+}
+
+func MaxBodyMiddleware(limit int64) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			r.Body = http.MaxBytesReader(w, r.Body, limit)
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+func (api *API) TimeoutMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx, cancel := context.WithTimeout(r.Context(), api.Config.HandlerTimeout)
+		defer cancel()
+
+		next.ServeHTTP(w, r.WithContext(ctx))
+
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			logger.WarnContext(r.Context(), "Обработка %s %s прервана по таймауту %s", r.Method, r.URL.Path, api.Config.HandlerTimeout)
 		}
 	})
 }
@@ -0,0 +1,235 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"mime"
+	"net/http"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/Halturshik/EM-test-task/GO/database"
+	"github.com/Halturshik/EM-test-task/GO/logger"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+)
+
+// @Summary Частично обновить подписку
+// @Description Изменяет подписку по правилам JSON Merge Patch (RFC 7396). Значение null в end_date делает подписку бессрочной. Дату начала можно перенести только у еще не начавшейся подписки
+// @Tags subscriptions
+// @Accept application/merge-patch+json
+// @Produce json
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Param user_id path string true "UUID пользователя"
+// @Param service_name path string true "Название сервиса"
+// @Param Idempotency-Key header string false "Ключ идемпотентности для безопасного повтора запроса"
+// @Param If-Match header string false "ETag подписки, полученный из GET"
+// @Param body body api.PatchSubRequest true "Изменяемые поля подписки"
+// @Success 200 {object} api.UpdateSubResponse "Сообщение об обновлении подписки"
+// @Failure 400 {object} api.ErrorResponse "Некорректные данные запроса"
+// @Failure 404 {object} api.ErrorResponse "Подписка не найдена"
+// @Failure 409 {object} api.ErrorResponse "Подписка уже началась или пересекается с другой"
+// @Failure 412 {object} api.ErrorResponse "Подписка была изменена другим запросом"
+// @Failure 415 {object} api.ErrorResponse "Неподдерживаемый тип содержимого"
+// @Failure 401 {object} api.ErrorResponse "Не указаны или недействительны учетные данные"
+// @Failure 403 {object} api.ErrorResponse "Недостаточно прав"
+// @Failure 413 {object} api.ErrorResponse "Тело запроса превышает допустимый размер"
+// @Failure 429 {object} api.ErrorResponse "Превышен лимит запросов"
+// @Failure 500 {object} api.ErrorResponse "Внутренняя ошибка сервера"
+// @Router /users/{user_id}/subscriptions/{service_name} [patch]
+func (api *API) PatchSubscriptionHandler(w http.ResponseWriter, r *http.Request) {
+	userIDStr := chi.URLParam(r, "user_id")
+	serviceName := strings.TrimSpace(chi.URLParam(r, "service_name"))
+
+	if strings.TrimSpace(userIDStr) == "" || serviceName == "" {
+		logger.WarnContext(r.Context(), "Ошибка: не указан uuid пользователя или название сервиса")
+		writeJSON(w, http.StatusBadRequest, map[string]any{"error": "Не указан идентификатор пользователя или название сервиса подписки"})
+		return
+	}
+
+	userID, err := uuid.Parse(userIDStr)
+	if err != nil {
+		logger.WarnContext(r.Context(), "Ошибка: некорректный формат uuid: %v", err)
+		writeJSON(w, http.StatusBadRequest, map[string]any{"error": "Некорректный формат идентификатора пользователя"})
+		return
+	}
+
+	reSN := regexp.MustCompile(`^[A-Za-z0-9 ]+$`)
+	if !reSN.MatchString(serviceName) {
+		logger.WarnContext(r.Context(), "Ошибка: в названии сервиса используются недопустимые символы")
+		writeJSON(w, http.StatusBadRequest, map[string]any{"error": "Недопустимое название сервиса: используйте только буквы, цифры и пробелы"})
+		return
+	}
+
+	mediaType, _, err := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if err != nil || (mediaType != "application/merge-patch+json" && mediaType != "application/json") {
+		logger.WarnContext(r.Context(), "Ошибка: неподдерживаемый Content-Type %q", r.Header.Get("Content-Type"))
+		writeJSON(w, http.StatusUnsupportedMediaType, map[string]any{"error": "Используйте Content-Type application/merge-patch+json"})
+		return
+	}
+
+	expectedVersion, err := parseIfMatch(r)
+	if err != nil {
+		logger.WarnContext(r.Context(), "Ошибка: некорректный заголовок If-Match")
+		writeJSON(w, http.StatusBadRequest, map[string]any{"error": "Некорректный заголовок If-Match"})
+		return
+	}
+
+	var req map[string]json.RawMessage
+	if !decodeJSON(w, r, &req) {
+		return
+	}
+	if req == nil {
+		logger.WarnContext(r.Context(), "Ошибка: тело запроса не является JSON-объектом")
+		writeJSON(w, http.StatusBadRequest, map[string]any{"error": "Некорректно оформлено тело запроса"})
+		return
+	}
+
+	if len(req) == 0 {
+		logger.WarnContext(r.Context(), "Ошибка: не указаны поля для изменения")
+		writeJSON(w, http.StatusBadRequest, map[string]any{"error": "Не заполнены поля для обновления"})
+		return
+	}
+
+	for field := range req {
+		if field != "price" && field != "start_date" && field != "end_date" {
+			logger.WarnContext(r.Context(), "Ошибка: неизвестное поле %q в теле запроса", field)
+			writeJSON(w, http.StatusBadRequest, map[string]any{"error": "Неизвестное поле: " + field})
+			return
+		}
+	}
+
+	now := time.Now()
+	currentMonth := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+
+	var patch database.SubPatch
+
+	if raw, ok := req["price"]; ok {
+		var price *int
+		if err := json.Unmarshal(raw, &price); err != nil || price == nil {
+			logger.WarnContext(r.Context(), "Ошибка: некорректное значение price")
+			writeJSON(w, http.StatusBadRequest, map[string]any{"error": "Уровень подписки нельзя удалить, укажите одно из значений: 50, 100, 200"})
+			return
+		}
+
+		validPrices := map[int]bool{50: true, 100: true, 200: true}
+		if !validPrices[*price] {
+			logger.WarnContext(r.Context(), "Ошибка: выбран несуществующий уровень подписки")
+			writeJSON(w, http.StatusBadRequest, map[string]any{"error": "Выберите допустимый уровень подписки: Базовый (50), Продвинутый (100), Премиум (200)"})
+			return
+		}
+		patch.Price = price
+	}
+
+	if raw, ok := req["start_date"]; ok {
+		var startStr *string
+		if err := json.Unmarshal(raw, &startStr); err != nil || startStr == nil {
+			logger.WarnContext(r.Context(), "Ошибка: некорректное значение start_date")
+			writeJSON(w, http.StatusBadRequest, map[string]any{"error": "Дату начала действия подписки нельзя удалить"})
+			return
+		}
+
+		start, err := time.Parse("01-2006", *startStr)
+		if err != nil {
+			logger.WarnContext(r.Context(), "Ошибка: некорректный формат даты начала подписки")
+			writeJSON(w, http.StatusBadRequest, map[string]any{"error": "Неверный формат даты начала действия подписки (используйте месяц-год)"})
+			return
+		}
+
+		if !start.After(currentMonth) {
+			logger.WarnContext(r.Context(), "Ошибка: дата начала подписки переносится на текущий или прошедший месяц")
+			writeJSON(w, http.StatusBadRequest, map[string]any{"error": "Дату начала подписки можно перенести только на один из будущих месяцев"})
+			return
+		}
+		patch.StartDate = &start
+	}
+
+	if raw, ok := req["end_date"]; ok {
+		var endStr *string
+		if err := json.Unmarshal(raw, &endStr); err != nil {
+			logger.WarnContext(r.Context(), "Ошибка: некорректное значение end_date")
+			writeJSON(w, http.StatusBadRequest, map[string]any{"error": "Неверный формат даты окончания действия подписки (используйте месяц-год)"})
+			return
+		}
+
+		patch.EndDateSet = true
+		if endStr != nil {
+			t, err := time.Parse("01-2006", *endStr)
+			if err != nil {
+				logger.WarnContext(r.Context(), "Ошибка: некорректный формат даты конца подписки")
+				writeJSON(w, http.StatusBadRequest, map[string]any{"error": "Неверный формат даты окончания действия подписки (используйте месяц-год)"})
+				return
+			}
+
+			if t.Before(currentMonth) {
+				logger.WarnContext(r.Context(), "Ошибка: дата конца подписки в прошлом")
+				writeJSON(w, http.StatusBadRequest, map[string]any{"error": "Дата окончания подписки не может быть раньше текущего месяца"})
+				return
+			}
+			endOfMonth := time.Date(t.Year(), t.Month()+1, 0, 23, 59, 59, 0, t.Location())
+			patch.EndDate = &endOfMonth
+		}
+	}
+
+	result, err := api.Store.PatchSubscription(r.Context(), userID, serviceName, patch, expectedVersion)
+	if err != nil {
+		switch {
+		case errors.Is(err, database.ErrSubNotFound):
+			logger.WarnContext(r.Context(), "Ошибка: активная подписка не найдена")
+			writeJSON(w, http.StatusNotFound, map[string]any{"error": "Активная подписка не найдена"})
+		case errors.Is(err, database.ErrSubVersionMismatch):
+			logger.WarnContext(r.Context(), "Ошибка: версия подписки не совпадает с If-Match")
+			writeJSON(w, http.StatusPreconditionFailed, map[string]any{"error": "Подписка была изменена другим запросом. Получите актуальную версию и повторите попытку"})
+		case errors.Is(err, database.ErrSubAlreadyStarted):
+			logger.WarnContext(r.Context(), "Ошибка: попытка перенести дату начала уже действующей подписки")
+			writeJSON(w, http.StatusConflict, map[string]any{"error": "Подписка уже действует, дату ее начала изменить нельзя"})
+		case errors.Is(err, database.ErrSubOverlapExist):
+			logger.WarnContext(r.Context(), "Ошибка: измененная подписка пересекается с другой")
+			writeJSON(w, http.StatusConflict, map[string]any{"error": "Новый период действия подписки пересекается с существующей подпиской"})
+		case errors.Is(err, database.ErrSubInvalidPeriod):
+			logger.WarnContext(r.Context(), "Ошибка: дата окончания подписки раньше даты начала")
+			writeJSON(w, http.StatusBadRequest, map[string]any{"error": "Дата окончания действия подписки не может быть раньше даты ее начала действия"})
+		default:
+			logger.ErrorContext(r.Context(), "Ошибка при обновлении подписки: %v", err)
+			writeJSON(w, http.StatusInternalServerError, map[string]any{"error": "Не удалось обновить подписку. Повторите попытку позже"})
+		}
+		return
+	}
+
+	var parts []string
+
+	if result.StartDateChanged {
+		parts = append(parts, "Дата начала подписки изменена")
+	}
+
+	switch result.OpType {
+	case "upgrade":
+		parts = append(parts, "Уровень подписки повышен и уже действует")
+	case "downgrade":
+		parts = append(parts, "Уровень подписки понижен, но вступит в силу в следующем месяце. До конца месяца сохраняется текущий уровень подписки")
+	case "rollback":
+		parts = append(parts, "Вернули прежний уровень подписки")
+	}
+
+	if result.EndDateChanged {
+		parts = append(parts, "Дата окончания подписки изменена")
+	}
+
+	if len(parts) == 0 {
+		logger.WarnContext(r.Context(), "Ошибка: подписка уже соответствует поступившим параметрам")
+		writeJSON(w, http.StatusOK, map[string]any{"message": "Выбранная подписка уже соответствует указанным параметрам"})
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]any{"message": strings.Join(parts, ". ")})
+	recordSubscriptionOperation(result.OpType)
+	logger.InfoContext(r.Context(), "Частично обновлена подписка пользователя %s на сервис %s: тип операции=%s, startDateChanged=%t, priceChanged=%t, endDateChanged=%t",
+		userID, serviceName, result.OpType, result.StartDateChanged, result.PriceChanged, result.EndDateChanged)
+
+	if result.OpType == "upgrade" || result.StartDateChanged {
+		api.checkBudgets(context.WithoutCancel(r.Context()), userID)
+	}
+}
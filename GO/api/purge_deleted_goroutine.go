@@ -0,0 +1,57 @@
+package api
+
+import (
+	"context"
+	"time"
+
+	"github.com/Halturshik/EM-test-task/GO/database"
+	"github.com/Halturshik/EM-test-task/GO/logger"
+	"github.com/Halturshik/EM-test-task/config"
+)
+
+var deletedPurgeCancel context.CancelFunc
+
+func StartDeletedSubsPurge(store *database.Store, cfg *config.Config) {
+	ctx, cancel := context.WithCancel(context.Background())
+	deletedPurgeCancel = cancel
+
+	go func() {
+		ticker := time.NewTicker(cfg.SoftDeletePurgeInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ticker.C:
+				purged, err := store.PurgeDeletedSubscriptions(ctx, cfg.SoftDeleteRetention)
+				if err != nil {
+					logger.Error("Ошибка очистки удаленных подписок: %v", err)
+				} else if purged > 0 {
+					logger.Info("Окончательно удалено подписок с истекшим сроком хранения: %d", purged)
+				}
+
+				expired, err := store.PurgeExpiredIdempotencyKeys(ctx)
+				if err != nil {
+					logger.Error("Ошибка очистки просроченных ключей идемпотентности: %v", err)
+				} else if expired > 0 {
+					logger.Info("Удалено просроченных ключей идемпотентности: %d", expired)
+				}
+
+				deliveries, events, err := store.PurgeWebhookHistory(ctx, cfg.WebhookRetention)
+				if err != nil {
+					logger.Error("Ошибка очистки истории вебхуков: %v", err)
+				} else if deliveries > 0 || events > 0 {
+					logger.Info("Удалено завершенных доставок вебхуков: %d, событий outbox: %d", deliveries, events)
+				}
+			case <-ctx.Done():
+				logger.Info("Фоновая очистка удаленных подписок остановлена")
+				return
+			}
+		}
+	}()
+}
+
+func StopDeletedSubsPurge() {
+	if deletedPurgeCancel != nil {
+		deletedPurgeCancel()
+	}
+}
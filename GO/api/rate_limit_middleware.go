@@ -0,0 +1,130 @@
+package api
+
+import (
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/Halturshik/EM-test-task/GO/logger"
+	"github.com/Halturshik/EM-test-task/config"
+)
+
+type tokenBucket struct {
+	tokens  float64
+	updated time.Time
+}
+
+type rateLimiter struct {
+	limit  int
+	period time.Duration
+
+	mu        sync.Mutex
+	buckets   map[string]*tokenBucket
+	lastSweep time.Time
+}
+
+func newRateLimiter(limit config.RateLimit) *rateLimiter {
+	return &rateLimiter{
+		limit:     limit.Requests,
+		period:    limit.Period,
+		buckets:   make(map[string]*tokenBucket),
+		lastSweep: time.Now(),
+	}
+}
+
+func (l *rateLimiter) allow(key string, now time.Time) (bool, int, time.Duration, time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	rate := float64(l.limit) / l.period.Seconds()
+
+	if now.Sub(l.lastSweep) > 2*l.period {
+		for k, b := range l.buckets {
+			if now.Sub(b.updated) > l.period {
+				delete(l.buckets, k)
+			}
+		}
+		l.lastSweep = now
+	}
+
+	b, ok := l.buckets[key]
+	if !ok {
+		b = &tokenBucket{tokens: float64(l.limit), updated: now}
+		l.buckets[key] = b
+	}
+
+	b.tokens = math.Min(float64(l.limit), b.tokens+now.Sub(b.updated).Seconds()*rate)
+	b.updated = now
+
+	allowed := b.tokens >= 1
+	if allowed {
+		b.tokens--
+	}
+
+	var retryAfter time.Duration
+	if !allowed {
+		retryAfter = time.Duration((1 - b.tokens) / rate * float64(time.Second))
+	}
+	reset := time.Duration((float64(l.limit) - b.tokens) / rate * float64(time.Second))
+
+	return allowed, int(b.tokens), retryAfter, reset
+}
+
+func (api *API) RateLimit(group string) func(http.Handler) http.Handler {
+	limit := api.Config.RateLimitDefault
+	switch group {
+	case "ip":
+		limit = api.Config.RateLimitIP
+	case "write":
+		limit = api.Config.RateLimitWrite
+	case "reports":
+		limit = api.Config.RateLimitReports
+	}
+
+	if limit.Requests == 0 {
+		return func(next http.Handler) http.Handler { return next }
+	}
+
+	limiter := newRateLimiter(limit)
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			// Группа ip стоит до аутентификации и ограничивает в том числе запросы
+			// без учетных данных или с неверным ключом, остальные — по клиенту.
+			key := clientIP(r)
+			if p := principalFromContext(r.Context()); p != nil && group != "ip" {
+				key = p.Actor
+			}
+
+			allowed, remaining, retryAfter, reset := limiter.allow(key, time.Now())
+
+			w.Header().Set("RateLimit-Limit", strconv.Itoa(limit.Requests))
+			w.Header().Set("RateLimit-Remaining", strconv.Itoa(remaining))
+			w.Header().Set("RateLimit-Reset", strconv.Itoa(int(math.Ceil(reset.Seconds()))))
+
+			if !allowed {
+				w.Header().Set("Retry-After", strconv.Itoa(ceilSeconds(retryAfter)))
+				logger.WarnContext(r.Context(), "Ошибка: превышен лимит запросов группы %s для %s", group, key)
+				writeJSON(w, http.StatusTooManyRequests, map[string]any{"error": "Слишком много запросов. Повторите попытку позже"})
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+func clientIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return "ip:" + r.RemoteAddr
+	}
+	return "ip:" + host
+}
+
+func ceilSeconds(d time.Duration) int {
+	return max(int(math.Ceil(d.Seconds())), 1)
+}
@@ -0,0 +1,91 @@
+package api
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Halturshik/EM-test-task/config"
+)
+
+func TestRateLimiterAllow(t *testing.T) {
+	start := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	type step struct {
+		key           string
+		after         time.Duration
+		wantAllowed   bool
+		wantRemaining int
+		wantRetry     time.Duration
+	}
+
+	tests := []struct {
+		name  string
+		limit config.RateLimit
+		steps []step
+	}{
+		{
+			name:  "запас исчерпывается и отказ сообщает время ожидания",
+			limit: config.RateLimit{Requests: 3, Period: 3 * time.Second},
+			steps: []step{
+				{key: "a", wantAllowed: true, wantRemaining: 2},
+				{key: "a", wantAllowed: true, wantRemaining: 1},
+				{key: "a", wantAllowed: true, wantRemaining: 0},
+				{key: "a", wantAllowed: false, wantRemaining: 0, wantRetry: time.Second},
+			},
+		},
+		{
+			name:  "токены восстанавливаются со временем",
+			limit: config.RateLimit{Requests: 2, Period: 2 * time.Second},
+			steps: []step{
+				{key: "a", wantAllowed: true, wantRemaining: 1},
+				{key: "a", wantAllowed: true, wantRemaining: 0},
+				{key: "a", wantAllowed: false, wantRetry: time.Second},
+				{key: "a", after: time.Second, wantAllowed: true, wantRemaining: 0},
+				{key: "a", after: time.Second + 500*time.Millisecond, wantAllowed: false, wantRetry: 500 * time.Millisecond},
+				{key: "a", after: 10 * time.Second, wantAllowed: true, wantRemaining: 1},
+			},
+		},
+		{
+			name:  "ключи ограничиваются независимо",
+			limit: config.RateLimit{Requests: 1, Period: time.Minute},
+			steps: []step{
+				{key: "a", wantAllowed: true},
+				{key: "a", wantAllowed: false, wantRetry: time.Minute},
+				{key: "b", wantAllowed: true},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l := newRateLimiter(tt.limit)
+			for i, s := range tt.steps {
+				allowed, remaining, retryAfter, _ := l.allow(s.key, start.Add(s.after))
+				if allowed != s.wantAllowed {
+					t.Fatalf("шаг %d: allowed = %v, ожидалось %v", i, allowed, s.wantAllowed)
+				}
+				if remaining != s.wantRemaining {
+					t.Errorf("шаг %d: remaining = %d, ожидалось %d", i, remaining, s.wantRemaining)
+				}
+				if retryAfter != s.wantRetry {
+					t.Errorf("шаг %d: retryAfter = %v, ожидалось %v", i, retryAfter, s.wantRetry)
+				}
+			}
+		})
+	}
+}
+
+func TestRateLimiterSweepsIdleBuckets(t *testing.T) {
+	start := time.Now()
+	l := newRateLimiter(config.RateLimit{Requests: 1, Period: time.Second})
+
+	l.allow("idle", start)
+	l.allow("active", start.Add(3*time.Second))
+
+	if _, ok := l.buckets["idle"]; ok {
+		t.Fatal("неактивный ключ не удален")
+	}
+	if _, ok := l.buckets["active"]; !ok {
+		t.Fatal("активный ключ удален")
+	}
+}
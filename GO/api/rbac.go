@@ -0,0 +1,39 @@
+package api
+
+import (
+	"context"
+	"slices"
+	"sync"
+	"time"
+)
+
+type rolePolicy struct {
+	mu          sync.Mutex
+	permissions map[string][]string
+	loadedAt    time.Time
+}
+
+func (api *API) permissionsForRoles(ctx context.Context, roles []string) ([]string, error) {
+	api.policy.mu.Lock()
+	defer api.policy.mu.Unlock()
+
+	if api.policy.permissions == nil || time.Since(api.policy.loadedAt) > api.Config.RBACPolicyTTL {
+		permissions, err := api.Store.GetRolePermissions(ctx)
+		if err != nil {
+			return nil, err
+		}
+		api.policy.permissions = permissions
+		api.policy.loadedAt = time.Now()
+	}
+
+	var result []string
+	for _, role := range roles {
+		for _, permission := range api.policy.permissions[role] {
+			if !slices.Contains(result, permission) {
+				result = append(result, permission)
+			}
+		}
+	}
+
+	return result, nil
+}
@@ -0,0 +1,98 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/Halturshik/EM-test-task/GO/database"
+	"github.com/Halturshik/EM-test-task/GO/logger"
+	"github.com/Halturshik/EM-test-task/GO/notify"
+	"github.com/Halturshik/EM-test-task/config"
+)
+
+var (
+	reminderSchedulerCancel context.CancelFunc
+	reminderSchedulerDone   sync.WaitGroup
+)
+
+func StartReminderScheduler(store *database.Store, notifier notify.Notifier, cfg *config.Config) {
+	ctx, cancel := context.WithCancel(context.Background())
+	reminderSchedulerCancel = cancel
+
+	requireEmail := cfg.Notifier == "smtp"
+
+	reminderSchedulerDone.Add(1)
+	go func() {
+		defer reminderSchedulerDone.Done()
+
+		ticker := time.NewTicker(cfg.ReminderInterval)
+		defer ticker.Stop()
+
+		sendReminders(ctx, store, notifier, cfg.ReminderDaysAhead, requireEmail)
+
+		for {
+			select {
+			case <-ticker.C:
+				sendReminders(ctx, store, notifier, cfg.ReminderDaysAhead, requireEmail)
+			case <-ctx.Done():
+				logger.Info("Фоновая отправка напоминаний остановлена")
+				return
+			}
+		}
+	}()
+}
+
+func StopReminderScheduler() {
+	if reminderSchedulerCancel != nil {
+		reminderSchedulerCancel()
+		reminderSchedulerDone.Wait()
+	}
+}
+
+func sendReminders(ctx context.Context, store *database.Store, notifier notify.Notifier, daysAhead int, requireEmail bool) {
+	reminders, err := store.GetUpcomingReminders(ctx, daysAhead, requireEmail)
+	if err != nil {
+		logger.Error("Ошибка выборки подписок для напоминаний: %v", err)
+		return
+	}
+
+	sent := 0
+	for _, rem := range reminders {
+		if ctx.Err() != nil {
+			return
+		}
+
+		n := notify.Notification{
+			Kind:        rem.Kind,
+			UserID:      rem.UserID,
+			ServiceName: rem.ServiceName,
+			Date:        rem.TargetDate,
+			Price:       rem.Price,
+			NewPrice:    rem.NewPrice,
+		}
+		if rem.Email != nil {
+			n.Email = *rem.Email
+		}
+
+		if err := notifier.Notify(ctx, n); err != nil {
+			if errors.Is(err, notify.ErrNoRecipient) {
+				logger.Warn("Напоминание о подписке %d не отправлено: %v", rem.SubscriptionID, err)
+			} else {
+				logger.Error("Ошибка отправки напоминания о подписке %d: %v", rem.SubscriptionID, err)
+			}
+			continue
+		}
+
+		if err := store.MarkReminderSent(ctx, rem.SubscriptionID, rem.Kind, rem.TargetDate); err != nil {
+			logger.Error("Ошибка сохранения отметки о напоминании для подписки %d: %v", rem.SubscriptionID, err)
+			continue
+		}
+		sent++
+	}
+
+	if sent > 0 {
+		logger.Info("Отправлено напоминаний о подписках: %d", sent)
+	}
+}
@@ -0,0 +1,125 @@
+package api
+
+import (
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/Halturshik/EM-test-task/GO/logger"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+)
+
+// @Summary Помесячный отчет о расходах
+// @Description Возвращает помесячную таблицу расходов пользователя по каждому сервису и общую сумму за месяц. Позволяет увидеть, с какого месяца начала действовать новая цена после повышения уровня подписки
+// @Tags reports
+// @Produce json
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Param user_id path string true "UUID пользователя"
+// @Param from query string true "Начало периода (MM-YYYY)"
+// @Param to query string true "Окончание периода (MM-YYYY)"
+// @Success 200 {object} api.ReportResponse "Помесячный отчет"
+// @Failure 400 {object} api.ErrorResponse "Некорректные данные запроса"
+// @Failure 401 {object} api.ErrorResponse "Не указаны или недействительны учетные данные"
+// @Failure 403 {object} api.ErrorResponse "Недостаточно прав"
+// @Failure 429 {object} api.ErrorResponse "Превышен лимит запросов"
+// @Failure 500 {object} api.ErrorResponse "Внутренняя ошибка сервера"
+// @Router /users/{user_id}/report [get]
+func (api *API) GetReportHandler(w http.ResponseWriter, r *http.Request) {
+	userID, err := uuid.Parse(strings.TrimSpace(chi.URLParam(r, "user_id")))
+	if err != nil {
+		logger.WarnContext(r.Context(), "Ошибка: некорректный формат uuid: %v", err)
+		writeJSON(w, http.StatusBadRequest, map[string]any{"error": "Некорректный формат идентификатора пользователя"})
+		return
+	}
+
+	fromStr := strings.TrimSpace(r.URL.Query().Get("from"))
+	toStr := strings.TrimSpace(r.URL.Query().Get("to"))
+	if fromStr == "" || toStr == "" {
+		logger.WarnContext(r.Context(), "Ошибка: не указан период для отчета о расходах")
+		writeJSON(w, http.StatusBadRequest, map[string]any{"error": "Не указан период для отчета о расходах"})
+		return
+	}
+
+	fromDate, err := time.Parse("01-2006", fromStr)
+	if err != nil {
+		logger.WarnContext(r.Context(), "Ошибка: некорректный формат даты начала периода отчета")
+		writeJSON(w, http.StatusBadRequest, map[string]any{"error": "Неверный формат даты начала периода (используйте месяц-год)"})
+		return
+	}
+
+	toDate, err := time.Parse("01-2006", toStr)
+	if err != nil {
+		logger.WarnContext(r.Context(), "Ошибка: некорректный формат даты окончания периода отчета")
+		writeJSON(w, http.StatusBadRequest, map[string]any{"error": "Неверный формат даты окончания периода (используйте месяц-год)"})
+		return
+	}
+
+	if toDate.Before(fromDate) {
+		logger.WarnContext(r.Context(), "Ошибка: дата окончания периода отчета раньше даты начала")
+		writeJSON(w, http.StatusBadRequest, map[string]any{"error": "Дата окончания периода не может быть раньше даты начала периода"})
+		return
+	}
+
+	now := time.Now()
+	if toDate.After(time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)) {
+		logger.WarnContext(r.Context(), "Ошибка: дата окончания периода отчета больше текущего месяца")
+		writeJSON(w, http.StatusBadRequest, map[string]any{"error": "Дата окончания периода не может быть больше текущего месяца"})
+		return
+	}
+
+	if toDate.After(fromDate.AddDate(0, 119, 0)) {
+		logger.WarnContext(r.Context(), "Ошибка: слишком длинный период отчета")
+		writeJSON(w, http.StatusBadRequest, map[string]any{"error": "Период отчета не может превышать 120 месяцев"})
+		return
+	}
+
+	report, err := api.Store.GetMonthlySpendReport(r.Context(), userID, fromDate, toDate)
+	if err != nil {
+		logger.ErrorContext(r.Context(), "Ошибка при построении отчета о расходах: %v", err)
+		writeJSON(w, http.StatusInternalServerError, map[string]any{"error": "Ошибка при построении отчета о расходах. Повторите попытку позже"})
+		return
+	}
+
+	type reportMonth struct {
+		Month    string         `json:"month"`
+		Services map[string]int `json:"services"`
+		Total    int            `json:"total"`
+	}
+
+	resp := struct {
+		From          string         `json:"from"`
+		To            string         `json:"to"`
+		Services      []string       `json:"services"`
+		Months        []reportMonth  `json:"months"`
+		ServiceTotals map[string]int `json:"service_totals"`
+		Total         int            `json:"total"`
+	}{
+		From:          fromDate.Format("01-2006"),
+		To:            toDate.Format("01-2006"),
+		Services:      []string{},
+		Months:        make([]reportMonth, 0, len(report)),
+		ServiceTotals: make(map[string]int),
+	}
+
+	for _, m := range report {
+		resp.Months = append(resp.Months, reportMonth{
+			Month:    m.Month.Format("01-2006"),
+			Services: m.Services,
+			Total:    m.Total,
+		})
+		for name, price := range m.Services {
+			if _, ok := resp.ServiceTotals[name]; !ok {
+				resp.Services = append(resp.Services, name)
+			}
+			resp.ServiceTotals[name] += price
+		}
+		resp.Total += m.Total
+	}
+	sort.Strings(resp.Services)
+
+	writeJSON(w, http.StatusOK, resp)
+	logger.InfoContext(r.Context(), "Выдан отчет о расходах пользователя %s за период %s - %s. Сумма: %d", userID, fromStr, toStr, resp.Total)
+}
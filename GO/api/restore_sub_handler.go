@@ -0,0 +1,100 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/Halturshik/EM-test-task/GO/database"
+	"github.com/Halturshik/EM-test-task/GO/logger"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+)
+
+// @Summary Восстановить удаленную подписку
+// @Description Восстанавливает удаленную подписку пользователя по дате начала действия, если срок хранения удаленных подписок еще не истек
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Param user_id path string true "UUID пользователя"
+// @Param service_name path string true "Название сервиса"
+// @Param body body api.RestoreSubRequest true "Дата начала подписки для восстановления"
+// @Success 200 {object} api.RestoreSubResponse "Подписка успешно восстановлена"
+// @Failure 400 {object} api.ErrorResponse "Некорректные данные запроса"
+// @Failure 404 {object} api.ErrorResponse "Удаленная подписка не найдена"
+// @Failure 409 {object} api.ErrorResponse "Подписка пересекается с существующей"
+// @Failure 401 {object} api.ErrorResponse "Не указаны или недействительны учетные данные"
+// @Failure 403 {object} api.ErrorResponse "Недостаточно прав"
+// @Failure 413 {object} api.ErrorResponse "Тело запроса превышает допустимый размер"
+// @Failure 429 {object} api.ErrorResponse "Превышен лимит запросов"
+// @Failure 500 {object} api.ErrorResponse "Внутренняя ошибка сервера"
+// @Router /admin/users/{user_id}/subscriptions/{service_name}/restore [post]
+func (api *API) RestoreSubscriptionHandler(w http.ResponseWriter, r *http.Request) {
+	userIDStr := chi.URLParam(r, "user_id")
+	serviceName := strings.TrimSpace(chi.URLParam(r, "service_name"))
+
+	if strings.TrimSpace(userIDStr) == "" || serviceName == "" {
+		logger.WarnContext(r.Context(), "Ошибка: не указан uuid пользователя или название сервиса")
+		writeJSON(w, http.StatusBadRequest, map[string]any{"error": "Не указан идентификатор пользователя или название сервиса подписки"})
+		return
+	}
+
+	userID, err := uuid.Parse(userIDStr)
+	if err != nil {
+		logger.WarnContext(r.Context(), "Ошибка: некорректный формат uuid: %v", err)
+		writeJSON(w, http.StatusBadRequest, map[string]any{"error": "Некорректный формат идентификатора пользователя"})
+		return
+	}
+
+	reSN := regexp.MustCompile(`^[A-Za-z0-9 ]+$`)
+	if !reSN.MatchString(serviceName) {
+		logger.WarnContext(r.Context(), "Ошибка: в названии сервиса используются недопустимые символы")
+		writeJSON(w, http.StatusBadRequest, map[string]any{"error": "Недопустимое название сервиса: используйте только буквы, цифры и пробелы"})
+		return
+	}
+
+	var req struct {
+		StartDate string `json:"start_date"`
+	}
+
+	if !decodeJSON(w, r, &req) {
+		return
+	}
+
+	if strings.TrimSpace(req.StartDate) == "" {
+		logger.WarnContext(r.Context(), "Ошибка: не указана дата начала подписки для восстановления")
+		writeJSON(w, http.StatusBadRequest, map[string]any{"error": "Не указана дата начала действия подписки, которую вы хотите восстановить"})
+		return
+	}
+
+	startDate, err := time.Parse("01-2006", req.StartDate)
+	if err != nil {
+		logger.WarnContext(r.Context(), "Ошибка: некорректный формат даты начала подписки для восстановления")
+		writeJSON(w, http.StatusBadRequest, map[string]any{"error": "Неверный формат даты начала действия подписки (используйте месяц-год)"})
+		return
+	}
+
+	err = api.Store.RestoreSubscription(r.Context(), userID, serviceName, startDate)
+	if err != nil {
+		switch {
+		case errors.Is(err, database.ErrSubNotFound):
+			logger.WarnContext(r.Context(), "Ошибка: удаленная подписка не найдена")
+			writeJSON(w, http.StatusNotFound, map[string]any{"error": "Удаленная подписка не найдена"})
+		case errors.Is(err, database.ErrSubOverlapExist):
+			logger.WarnContext(r.Context(), "Ошибка: восстанавливаемая подписка пересекается с другой")
+			writeJSON(w, http.StatusConflict, map[string]any{"error": "Период действия восстанавливаемой подписки пересекается с существующей подпиской"})
+		default:
+			logger.ErrorContext(r.Context(), "Ошибка при восстановлении подписки: %v", err)
+			writeJSON(w, http.StatusInternalServerError, map[string]any{"error": "Ошибка при восстановлении подписки. Повторите попытку позже"})
+		}
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]any{"message": "Подписка успешно восстановлена"})
+	recordSubscriptionOperation("restore")
+	logger.InfoContext(r.Context(), "Восстановлена подписка пользователя %s на сервис %s с датой начала %s", userID, serviceName, req.StartDate)
+}
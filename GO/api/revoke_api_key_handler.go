@@ -0,0 +1,50 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/Halturshik/EM-test-task/GO/database"
+	"github.com/Halturshik/EM-test-task/GO/logger"
+	"github.com/go-chi/chi/v5"
+)
+
+// @Summary Отозвать API-ключ
+// @Description Отзывает API-ключ. Отозванный ключ больше не принимается, запись о нем сохраняется
+// @Tags admin
+// @Produce json
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Param key_id path int true "Идентификатор ключа"
+// @Success 200 {object} api.RevokeAPIKeyResponse "Ключ отозван"
+// @Failure 400 {object} api.ErrorResponse "Некорректные данные запроса"
+// @Failure 401 {object} api.ErrorResponse "Не указаны или недействительны учетные данные"
+// @Failure 403 {object} api.ErrorResponse "Недостаточно прав"
+// @Failure 404 {object} api.ErrorResponse "Ключ не найден или уже отозван"
+// @Failure 429 {object} api.ErrorResponse "Превышен лимит запросов"
+// @Failure 500 {object} api.ErrorResponse "Внутренняя ошибка сервера"
+// @Router /admin/api-keys/{key_id} [delete]
+func (api *API) RevokeAPIKeyHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(chi.URLParam(r, "key_id"))
+	if err != nil || id <= 0 {
+		logger.WarnContext(r.Context(), "Ошибка: некорректный идентификатор API-ключа")
+		writeJSON(w, http.StatusBadRequest, map[string]any{"error": "Некорректный идентификатор ключа"})
+		return
+	}
+
+	err = api.Store.RevokeAPIKey(r.Context(), id)
+	if errors.Is(err, database.ErrAPIKeyNotFound) {
+		logger.WarnContext(r.Context(), "Ошибка: API-ключ %d не найден или уже отозван", id)
+		writeJSON(w, http.StatusNotFound, map[string]any{"error": "Ключ не найден или уже отозван"})
+		return
+	}
+	if err != nil {
+		logger.ErrorContext(r.Context(), "Ошибка: не удалось отозвать API-ключ %d: %v", id, err)
+		writeJSON(w, http.StatusInternalServerError, map[string]any{"error": "Не удалось отозвать ключ. Повторите попытку позже"})
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]any{"message": "Ключ отозван"})
+	logger.InfoContext(r.Context(), "API-ключ %d отозван", id)
+}
@@ -0,0 +1,63 @@
+package api
+
+import (
+	"net/http"
+	"net/mail"
+	"strings"
+
+	"github.com/Halturshik/EM-test-task/GO/logger"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+)
+
+// @Summary Указать адрес для уведомлений
+// @Description Сохраняет адрес электронной почты, на который отправляются напоминания об окончании подписок и запланированном понижении уровня
+// @Tags users
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Param user_id path string true "UUID пользователя"
+// @Param body body api.SetContactRequest true "Адрес электронной почты"
+// @Success 200 {object} api.SetContactResponse "Адрес сохранен"
+// @Failure 400 {object} api.ErrorResponse "Некорректные данные запроса"
+// @Failure 401 {object} api.ErrorResponse "Не указаны или недействительны учетные данные"
+// @Failure 403 {object} api.ErrorResponse "Недостаточно прав"
+// @Failure 413 {object} api.ErrorResponse "Тело запроса превышает допустимый размер"
+// @Failure 429 {object} api.ErrorResponse "Превышен лимит запросов"
+// @Failure 500 {object} api.ErrorResponse "Внутренняя ошибка сервера"
+// @Router /users/{user_id}/contact [put]
+func (api *API) SetContactHandler(w http.ResponseWriter, r *http.Request) {
+	userIDStr := chi.URLParam(r, "user_id")
+
+	userID, err := uuid.Parse(strings.TrimSpace(userIDStr))
+	if err != nil {
+		logger.WarnContext(r.Context(), "Ошибка: некорректный формат uuid: %v", err)
+		writeJSON(w, http.StatusBadRequest, map[string]any{"error": "Некорректный формат идентификатора пользователя"})
+		return
+	}
+
+	var req struct {
+		Email string `json:"email"`
+	}
+
+	if !decodeJSON(w, r, &req) {
+		return
+	}
+
+	addr, err := mail.ParseAddress(strings.TrimSpace(req.Email))
+	if err != nil {
+		logger.WarnContext(r.Context(), "Ошибка: некорректный адрес электронной почты")
+		writeJSON(w, http.StatusBadRequest, map[string]any{"error": "Укажите корректный адрес электронной почты"})
+		return
+	}
+
+	if err := api.Store.SetUserContact(r.Context(), userID, addr.Address); err != nil {
+		logger.ErrorContext(r.Context(), "Ошибка: не удалось сохранить адрес для уведомлений: %v", err)
+		writeJSON(w, http.StatusInternalServerError, map[string]any{"error": "Не удалось сохранить адрес для уведомлений. Повторите попытку позже"})
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]any{"message": "Адрес для уведомлений сохранен"})
+	logger.InfoContext(r.Context(), "Сохранен адрес для уведомлений пользователя %s", userID)
+}
@@ -2,45 +2,90 @@ package api
 
 import (
 	"context"
+	"sync/atomic"
 	"time"
 
 	"github.com/Halturshik/EM-test-task/GO/database"
 	"github.com/Halturshik/EM-test-task/GO/logger"
 )
 
-var monthlySyncCancel context.CancelFunc
+// Цикл синхронизации просыпается каждые monthlySyncHeartbeatInterval и
+// отмечает, что жив. Если отметки нет дольше monthlySyncStaleAfter (зависла
+// синхронизация или цикл остановлен), /readyz сообщает о неготовности.
+const (
+	monthlySyncHeartbeatInterval = 30 * time.Second
+	monthlySyncStaleAfter        = 10 * time.Minute
+)
+
+var (
+	monthlySyncCancel    context.CancelFunc
+	monthlySyncHeartbeat atomic.Int64
+)
 
 func StartMonthlySync(store *database.Store) {
 	ctx, cancel := context.WithCancel(context.Background())
 	monthlySyncCancel = cancel
 
+	monthlySyncHeartbeat.Store(time.Now().UnixNano())
+
 	go func() {
+		defer monthlySyncHeartbeat.Store(0)
+
+		ticker := time.NewTicker(monthlySyncHeartbeatInterval)
+		defer ticker.Stop()
+
+		// Синхронизация при запуске догоняет смену месяца, пропущенную, пока
+		// сервис был остановлен, и сразу заполняет метрику времени синхронизации.
+		syncSubscriptionPrices(ctx, store)
+		next := nextMonthStart(time.Now())
+
 		for {
 			select {
 			case <-ctx.Done():
 				logger.Info("Фоновая синхронизация подписок остановлена")
 				return
-			default:
-				now := time.Now()
-				next := time.Date(now.Year(), now.Month()+1, 1, 0, 0, 0, 0, now.Location())
-				duration := time.Until(next)
-
-				select {
-				case <-time.After(duration):
-					if err := store.SyncSubscriptionPrices(context.Background()); err != nil {
-						logger.Error("Ошибка синхронизации подписок: %v", err)
-					} else {
-						logger.Info("Синхронизация подписок выполнена успешно")
-					}
-				case <-ctx.Done():
-					logger.Info("Фоновая синхронизация подписок остановлена")
-					return
+			case now := <-ticker.C:
+				monthlySyncHeartbeat.Store(now.UnixNano())
+				if now.Before(next) {
+					continue
+				}
+
+				syncSubscriptionPrices(ctx, store)
+
+				alerts, err := store.RecordBudgetAlerts(ctx, nil, time.Now())
+				if err != nil {
+					logger.Error("Ошибка проверки бюджетов: %v", err)
 				}
+				for _, a := range alerts {
+					logger.Warn("Превышен бюджет пользователя %s (service=%v): прогноз %d при лимите %d", a.UserID, a.ServiceName, a.ProjectedMonthlyCost, a.MonthlyLimit)
+				}
+
+				next = nextMonthStart(time.Now())
+				monthlySyncHeartbeat.Store(time.Now().UnixNano())
 			}
 		}
 	}()
 }
 
+func syncSubscriptionPrices(ctx context.Context, store *database.Store) {
+	if err := store.SyncSubscriptionPrices(ctx); err != nil {
+		logger.Error("Ошибка синхронизации подписок: %v", err)
+		return
+	}
+	lastPriceSyncTimestamp.SetToCurrentTime()
+	logger.Info("Синхронизация подписок выполнена успешно")
+}
+
+func nextMonthStart(now time.Time) time.Time {
+	return time.Date(now.Year(), now.Month()+1, 1, 0, 0, 0, 0, now.Location())
+}
+
+// MonthlySyncAlive сообщает, отмечался ли цикл синхронизации в пределах monthlySyncStaleAfter.
+func MonthlySyncAlive() bool {
+	last := monthlySyncHeartbeat.Load()
+	return last != 0 && time.Since(time.Unix(0, last)) < monthlySyncStaleAfter
+}
+
 func StopMonthlySync() {
 	if monthlySyncCancel != nil {
 		monthlySyncCancel()
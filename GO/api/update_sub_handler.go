@@ -1,7 +1,7 @@
 package api
 
 import (
-	"encoding/json"
+	"context"
 	"errors"
 	"net/http"
 	"regexp"
@@ -19,12 +19,22 @@ import (
 // @Tags subscriptions
 // @Accept json
 // @Produce json
+// @Security ApiKeyAuth
+// @Security BearerAuth
 // @Param user_id path string true "UUID пользователя"
 // @Param service_name path string true "Название сервиса"
+// @Param Idempotency-Key header string false "Ключ идемпотентности для безопасного повтора запроса"
+// @Param If-Match header string false "ETag подписки, полученный из GET"
 // @Param body body api.UpdateSubRequest true "Новые данные подписки"
 // @Success 200 {object} api.UpdateSubResponse "Сообщение об обновлении подписки"
 // @Failure 400 {object} api.ErrorResponse "Некорректные данные запроса"
 // @Failure 404 {object} api.ErrorResponse "Подписка не найдена"
+// @Failure 412 {object} api.ErrorResponse "Подписка была изменена другим запросом"
+// @Failure 422 {object} api.ErrorResponse "Ключ идемпотентности использован для другого запроса"
+// @Failure 401 {object} api.ErrorResponse "Не указаны или недействительны учетные данные"
+// @Failure 403 {object} api.ErrorResponse "Недостаточно прав"
+// @Failure 413 {object} api.ErrorResponse "Тело запроса превышает допустимый размер"
+// @Failure 429 {object} api.ErrorResponse "Превышен лимит запросов"
 // @Failure 500 {object} api.ErrorResponse "Внутренняя ошибка сервера"
 // @Router /users/{user_id}/subscriptions/{service_name} [put]
 func (api *API) UpdateSubscriptionHandler(w http.ResponseWriter, r *http.Request) {
@@ -32,45 +42,50 @@ func (api *API) UpdateSubscriptionHandler(w http.ResponseWriter, r *http.Request
 	serviceName := strings.TrimSpace(chi.URLParam(r, "service_name"))
 
 	if strings.TrimSpace(userIDStr) == "" || serviceName == "" {
-		logger.Warn("Ошибка: не указан uuid пользователя или название сервиса")
+		logger.WarnContext(r.Context(), "Ошибка: не указан uuid пользователя или название сервиса")
 		writeJSON(w, http.StatusBadRequest, map[string]any{"error": "Не указан идентификатор пользователя или название сервиса подписки"})
 		return
 	}
 
 	userID, err := uuid.Parse(userIDStr)
 	if err != nil {
-		logger.Warn("Ошибка: некорректный формат uuid: %v", err)
+		logger.WarnContext(r.Context(), "Ошибка: некорректный формат uuid: %v", err)
 		writeJSON(w, http.StatusBadRequest, map[string]any{"error": "Некорректный формат идентификатора пользователя"})
 		return
 	}
 
 	reSN := regexp.MustCompile(`^[A-Za-z0-9 ]+$`)
 	if !reSN.MatchString(serviceName) {
-		logger.Warn("Ошибка: в названии сервиса используются недопустимые символы")
+		logger.WarnContext(r.Context(), "Ошибка: в названии сервиса используются недопустимые символы")
 		writeJSON(w, http.StatusBadRequest, map[string]any{"error": "Недопустимое название сервиса: используйте только буквы, цифры и пробелы"})
 		return
 	}
 
+	expectedVersion, err := parseIfMatch(r)
+	if err != nil {
+		logger.WarnContext(r.Context(), "Ошибка: некорректный заголовок If-Match")
+		writeJSON(w, http.StatusBadRequest, map[string]any{"error": "Некорректный заголовок If-Match"})
+		return
+	}
+
 	var req struct {
 		NewPrice   *int    `json:"new_price,omitempty"`
 		NewEndDate *string `json:"new_end_date,omitempty"`
 	}
 
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		logger.Warn("Ошибка: не удалось прочитать тело запроса: %v", err)
-		writeJSON(w, http.StatusBadRequest, map[string]any{"error": "Некорректно оформлено тело запроса"})
+	if !decodeJSON(w, r, &req) {
 		return
 	}
 
 	if req.NewPrice == nil && req.NewEndDate == nil {
-		logger.Warn("Ошибка: не указаны поля для изменения")
+		logger.WarnContext(r.Context(), "Ошибка: не указаны поля для изменения")
 		writeJSON(w, http.StatusBadRequest, map[string]any{"error": "Не заполнены поля для обновления"})
 		return
 	}
 
 	validPrices := map[int]bool{50: true, 100: true, 200: true}
 	if req.NewPrice != nil && !validPrices[*req.NewPrice] {
-		logger.Warn("Ошибка: выбран несуществующий уровень подписки")
+		logger.WarnContext(r.Context(), "Ошибка: выбран несуществующий уровень подписки")
 		writeJSON(w, http.StatusBadRequest, map[string]any{"error": "Выберите допустимый уровень подписки: Базовый (50), Продвинутый (100), Премиум (200)"})
 		return
 	}
@@ -81,7 +96,7 @@ func (api *API) UpdateSubscriptionHandler(w http.ResponseWriter, r *http.Request
 		if strings.TrimSpace(*req.NewEndDate) != "" {
 			t, err := time.Parse("01-2006", *req.NewEndDate)
 			if err != nil {
-				logger.Warn("Ошибка: некорректный формат даты конца подписки")
+				logger.WarnContext(r.Context(), "Ошибка: некорректный формат даты конца подписки")
 				writeJSON(w, http.StatusBadRequest, map[string]any{"error": "Неверный формат даты окончания действия подписки (используйте месяц-год)"})
 				return
 			}
@@ -89,7 +104,7 @@ func (api *API) UpdateSubscriptionHandler(w http.ResponseWriter, r *http.Request
 			now := time.Now()
 			currentMonth := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, now.Location())
 			if t.Before(currentMonth) {
-				logger.Warn("Ошибка: дата конца подписки в прошлом")
+				logger.WarnContext(r.Context(), "Ошибка: дата конца подписки в прошлом")
 				writeJSON(w, http.StatusBadRequest, map[string]any{"error": "Дата окончания подписки не может быть раньше текущего месяца"})
 				return
 			}
@@ -99,21 +114,26 @@ func (api *API) UpdateSubscriptionHandler(w http.ResponseWriter, r *http.Request
 		}
 	}
 
-	priceChanged, endDateChanged, opType, err := api.Store.UpdateSubscription(r.Context(), userID, serviceName, req.NewPrice, newEndDateParsed, newEndDateProvided)
+	priceChanged, endDateChanged, opType, err := api.Store.UpdateSubscription(r.Context(), userID, serviceName, req.NewPrice, newEndDateParsed, newEndDateProvided, expectedVersion)
 	if err != nil {
 		if errors.Is(err, database.ErrSubNotFound) {
-			logger.Warn("Ошибка: активная подписка не найдена")
+			logger.WarnContext(r.Context(), "Ошибка: активная подписка не найдена")
 			writeJSON(w, http.StatusNotFound, map[string]any{"error": "Активная подписка не найдена"})
 			return
 		}
+		if errors.Is(err, database.ErrSubVersionMismatch) {
+			logger.WarnContext(r.Context(), "Ошибка: версия подписки не совпадает с If-Match")
+			writeJSON(w, http.StatusPreconditionFailed, map[string]any{"error": "Подписка была изменена другим запросом. Получите актуальную версию и повторите попытку"})
+			return
+		}
 
-		logger.Error("Ошибка при обновлении подписки: %v", err)
+		logger.ErrorContext(r.Context(), "Ошибка при обновлении подписки: %v", err)
 		writeJSON(w, http.StatusInternalServerError, map[string]any{"error": "Не удалось обновить подписку. Повторите попытку позже"})
 		return
 	}
 
 	if opType == "" && !priceChanged && !endDateChanged {
-		logger.Warn("Ошибка: подписка уже соответствует поступившим параметрам")
+		logger.WarnContext(r.Context(), "Ошибка: подписка уже соответствует поступившим параметрам")
 		writeJSON(w, http.StatusOK, map[string]any{"message": "Выбранная подписка уже соответствует указанным параметрам"})
 		return
 	}
@@ -136,8 +156,13 @@ func (api *API) UpdateSubscriptionHandler(w http.ResponseWriter, r *http.Request
 	msg := strings.Join(parts, ". ")
 
 	writeJSON(w, http.StatusOK, map[string]any{"message": msg})
+	recordSubscriptionOperation(opType)
 
 	if opType != "" || priceChanged || endDateChanged {
-		logger.Info("Обновлена подписка пользователя %s на сервис %s: тип операции=%s, priceChanged=%t, endDateChanged=%t", userID, serviceName, opType, priceChanged, endDateChanged)
+		logger.InfoContext(r.Context(), "Обновлена подписка пользователя %s на сервис %s: тип операции=%s, priceChanged=%t, endDateChanged=%t", userID, serviceName, opType, priceChanged, endDateChanged)
+	}
+
+	if opType == "upgrade" {
+		api.checkBudgets(context.WithoutCancel(r.Context()), userID)
 	}
 }
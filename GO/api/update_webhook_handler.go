@@ -0,0 +1,106 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/Halturshik/EM-test-task/GO/database"
+	"github.com/Halturshik/EM-test-task/GO/logger"
+	"github.com/go-chi/chi/v5"
+)
+
+// @Summary Изменить вебхук
+// @Description Изменяет адрес, секрет, фильтр типов событий или включает/отключает вебхук. Отключенный вебхук не получает событий, его доставки приостанавливаются
+// @Tags webhooks
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Param webhook_id path int true "Идентификатор вебхука"
+// @Param body body api.UpdateWebhookRequest true "Изменяемые поля вебхука"
+// @Success 200 {object} api.WebhookResponse "Обновленный вебхук"
+// @Failure 400 {object} api.ErrorResponse "Некорректные данные запроса"
+// @Failure 404 {object} api.ErrorResponse "Вебхук не найден"
+// @Failure 401 {object} api.ErrorResponse "Не указаны или недействительны учетные данные"
+// @Failure 403 {object} api.ErrorResponse "Недостаточно прав"
+// @Failure 413 {object} api.ErrorResponse "Тело запроса превышает допустимый размер"
+// @Failure 429 {object} api.ErrorResponse "Превышен лимит запросов"
+// @Failure 500 {object} api.ErrorResponse "Внутренняя ошибка сервера"
+// @Router /webhooks/{webhook_id} [patch]
+func (api *API) UpdateWebhookHandler(w http.ResponseWriter, r *http.Request) {
+	webhookID, err := strconv.Atoi(chi.URLParam(r, "webhook_id"))
+	if err != nil || webhookID <= 0 {
+		logger.WarnContext(r.Context(), "Ошибка: некорректный идентификатор вебхука")
+		writeJSON(w, http.StatusBadRequest, map[string]any{"error": "Некорректный идентификатор вебхука"})
+		return
+	}
+
+	var req struct {
+		URL        *string   `json:"url"`
+		Secret     *string   `json:"secret"`
+		EventTypes *[]string `json:"event_types"`
+		Enabled    *bool     `json:"enabled"`
+	}
+
+	if !decodeJSON(w, r, &req) {
+		return
+	}
+
+	if req.URL == nil && req.Secret == nil && req.EventTypes == nil && req.Enabled == nil {
+		logger.WarnContext(r.Context(), "Ошибка: не указаны поля для изменения вебхука")
+		writeJSON(w, http.StatusBadRequest, map[string]any{"error": "Не заполнены поля для обновления"})
+		return
+	}
+
+	if req.URL != nil {
+		trimmed := strings.TrimSpace(*req.URL)
+		if !validWebhookURL(trimmed) {
+			logger.WarnContext(r.Context(), "Ошибка: некорректный адрес вебхука")
+			writeJSON(w, http.StatusBadRequest, map[string]any{"error": "Укажите корректный адрес вебхука (http или https)"})
+			return
+		}
+
+		if err := checkWebhookURLHost(r.Context(), trimmed); err != nil {
+			logger.WarnContext(r.Context(), "Ошибка: недопустимый адрес вебхука: %v", err)
+			writeJSON(w, http.StatusBadRequest, map[string]any{"error": "Адрес вебхука должен указывать на публичный узел, доступный из интернета"})
+			return
+		}
+		req.URL = &trimmed
+	}
+
+	if req.Secret != nil && len(*req.Secret) < 16 {
+		logger.WarnContext(r.Context(), "Ошибка: слишком короткий секрет вебхука")
+		writeJSON(w, http.StatusBadRequest, map[string]any{"error": "Секрет вебхука должен содержать не менее 16 символов"})
+		return
+	}
+
+	if req.EventTypes != nil {
+		if bad, ok := validWebhookEventTypes(*req.EventTypes); !ok {
+			logger.WarnContext(r.Context(), "Ошибка: неизвестный тип события %q", bad)
+			writeJSON(w, http.StatusBadRequest, map[string]any{"error": "Неизвестный тип события: " + bad})
+			return
+		}
+	}
+
+	webhook, err := api.Store.UpdateWebhook(r.Context(), webhookID, database.WebhookPatch{
+		URL:        req.URL,
+		Secret:     req.Secret,
+		EventTypes: req.EventTypes,
+		Enabled:    req.Enabled,
+	})
+	if err != nil {
+		if errors.Is(err, database.ErrWebhookNotFound) {
+			logger.WarnContext(r.Context(), "Ошибка: вебхук %d не найден", webhookID)
+			writeJSON(w, http.StatusNotFound, map[string]any{"error": "Вебхук не найден"})
+			return
+		}
+		logger.ErrorContext(r.Context(), "Ошибка: не удалось обновить вебхук: %v", err)
+		writeJSON(w, http.StatusInternalServerError, map[string]any{"error": "Не удалось обновить вебхук. Повторите попытку позже"})
+		return
+	}
+
+	writeJSON(w, http.StatusOK, webhook)
+	logger.InfoContext(r.Context(), "Обновлен вебхук %d", webhookID)
+}
@@ -2,8 +2,14 @@ package api
 
 import (
 	"encoding/json"
-	"log"
+	"errors"
+	"fmt"
+	"io"
 	"net/http"
+	"reflect"
+	"strings"
+
+	"github.com/Halturshik/EM-test-task/GO/logger"
 )
 
 func writeJSON(w http.ResponseWriter, status int, data any) {
@@ -11,7 +17,7 @@ func writeJSON(w http.ResponseWriter, status int, data any) {
 
 	resp, err := json.Marshal(data)
 	if err != nil {
-		log.Println("Ошибка при формировании JSON:", err)
+		logger.Error("Ошибка при формировании JSON: %v", err)
 		w.WriteHeader(http.StatusInternalServerError)
 		resp = []byte(`{"error":"ошибка при формировании JSON"}`)
 	} else {
@@ -19,6 +25,64 @@ func writeJSON(w http.ResponseWriter, status int, data any) {
 	}
 
 	if _, err := w.Write(resp); err != nil {
-		log.Println("Ошибка при отправке ответа:", err)
+		logger.Error("Ошибка при отправке ответа: %v", err)
+	}
+}
+
+func decodeJSON(w http.ResponseWriter, r *http.Request, dst any) bool {
+	dec := json.NewDecoder(r.Body)
+	dec.DisallowUnknownFields()
+
+	err := dec.Decode(dst)
+	if err == nil {
+		if dec.Decode(&struct{}{}) != io.EOF {
+			err = errors.New("после JSON-объекта есть лишние данные")
+		} else {
+			return true
+		}
+	}
+
+	logger.WarnContext(r.Context(), "Ошибка: не удалось прочитать тело запроса: %v", err)
+
+	var maxErr *http.MaxBytesError
+	var typeErr *json.UnmarshalTypeError
+	switch {
+	case errors.As(err, &maxErr):
+		writeJSON(w, http.StatusRequestEntityTooLarge, map[string]any{"error": fmt.Sprintf("Тело запроса превышает допустимый размер %d байт", maxErr.Limit)})
+	case errors.As(err, &typeErr) && typeErr.Field != "":
+		writeJSON(w, http.StatusBadRequest, map[string]any{
+			"error": fmt.Sprintf("Поле %s имеет неверный тип: ожидается %s", typeErr.Field, jsonTypeName(typeErr.Type)),
+			"field": typeErr.Field,
+		})
+	case strings.HasPrefix(err.Error(), "json: unknown field "):
+		field := strings.Trim(strings.TrimPrefix(err.Error(), "json: unknown field "), `"`)
+		writeJSON(w, http.StatusBadRequest, map[string]any{"error": "Неизвестное поле: " + field, "field": field})
+	case errors.Is(err, io.EOF):
+		writeJSON(w, http.StatusBadRequest, map[string]any{"error": "Тело запроса не может быть пустым"})
+	default:
+		writeJSON(w, http.StatusBadRequest, map[string]any{"error": "Некорректно оформлено тело запроса"})
+	}
+
+	return false
+}
+
+func jsonTypeName(t reflect.Type) string {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	switch t.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return "число"
+	case reflect.String:
+		return "строка"
+	case reflect.Bool:
+		return "логическое значение"
+	case reflect.Slice, reflect.Array:
+		return "массив"
+	default:
+		return "объект"
 	}
 }
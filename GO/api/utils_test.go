@@ -0,0 +1,71 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestDecodeJSON(t *testing.T) {
+	type request struct {
+		ServiceName string  `json:"service_name"`
+		Price       int     `json:"price"`
+		EndDate     *string `json:"end_date"`
+	}
+
+	tests := []struct {
+		name       string
+		body       string
+		maxBytes   int64
+		wantOK     bool
+		wantStatus int
+		wantField  string
+		wantError  string
+	}{
+		{name: "корректное тело", body: `{"service_name":"Yandex Plus","price":400}`, wantOK: true},
+		{name: "пробелы после объекта", body: "{\"price\":1}\n  ", wantOK: true},
+		{name: "пустое тело", body: "", wantStatus: http.StatusBadRequest, wantError: "Тело запроса не может быть пустым"},
+		{name: "неизвестное поле", body: `{"price":1,"discount":5}`, wantStatus: http.StatusBadRequest, wantField: "discount", wantError: "Неизвестное поле: discount"},
+		{name: "неверный тип числа", body: `{"price":"400"}`, wantStatus: http.StatusBadRequest, wantField: "price", wantError: "Поле price имеет неверный тип: ожидается число"},
+		{name: "неверный тип строки", body: `{"service_name":12}`, wantStatus: http.StatusBadRequest, wantField: "service_name", wantError: "Поле service_name имеет неверный тип: ожидается строка"},
+		{name: "лишние данные", body: `{"price":1}{"price":2}`, wantStatus: http.StatusBadRequest, wantError: "Некорректно оформлено тело запроса"},
+		{name: "синтаксическая ошибка", body: `{"price":}`, wantStatus: http.StatusBadRequest, wantError: "Некорректно оформлено тело запроса"},
+		{name: "оборванный JSON", body: `{"price":1`, wantStatus: http.StatusBadRequest, wantError: "Некорректно оформлено тело запроса"},
+		{name: "превышен размер", body: `{"service_name":"` + strings.Repeat("a", 100) + `"}`, maxBytes: 32, wantStatus: http.StatusRequestEntityTooLarge, wantError: "Тело запроса превышает допустимый размер 32 байт"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest(http.MethodPost, "/subscriptions", strings.NewReader(tt.body))
+			if tt.maxBytes > 0 {
+				r.Body = http.MaxBytesReader(w, r.Body, tt.maxBytes)
+			}
+
+			var dst request
+			ok := decodeJSON(w, r, &dst)
+			if ok != tt.wantOK {
+				t.Fatalf("decodeJSON = %v, ожидалось %v (ответ %s)", ok, tt.wantOK, w.Body.String())
+			}
+			if tt.wantOK {
+				return
+			}
+
+			if w.Code != tt.wantStatus {
+				t.Errorf("статус = %d, ожидалось %d", w.Code, tt.wantStatus)
+			}
+			var resp map[string]string
+			if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+				t.Fatalf("ответ не JSON: %v", err)
+			}
+			if resp["error"] != tt.wantError {
+				t.Errorf("error = %q, ожидалось %q", resp["error"], tt.wantError)
+			}
+			if resp["field"] != tt.wantField {
+				t.Errorf("field = %q, ожидалось %q", resp["field"], tt.wantField)
+			}
+		})
+	}
+}
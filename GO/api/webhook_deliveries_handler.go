@@ -0,0 +1,116 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/Halturshik/EM-test-task/GO/database"
+	"github.com/Halturshik/EM-test-task/GO/logger"
+	"github.com/go-chi/chi/v5"
+)
+
+// @Summary Получить историю доставок вебхука
+// @Description Возвращает доставки событий на вебхук от новых к старым
+// @Tags webhooks
+// @Produce json
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Param webhook_id path int true "Идентификатор вебхука"
+// @Param page query int false "Номер страницы для пагинации" default(1)
+// @Success 200 {array} api.WebhookDeliveryResponse "История доставок"
+// @Failure 400 {object} api.ErrorResponse "Некорректный идентификатор вебхука"
+// @Failure 404 {object} api.ErrorResponse "Вебхук не найден"
+// @Failure 401 {object} api.ErrorResponse "Не указаны или недействительны учетные данные"
+// @Failure 403 {object} api.ErrorResponse "Недостаточно прав"
+// @Failure 429 {object} api.ErrorResponse "Превышен лимит запросов"
+// @Failure 500 {object} api.ErrorResponse "Внутренняя ошибка сервера"
+// @Router /webhooks/{webhook_id}/deliveries [get]
+func (api *API) GetWebhookDeliveriesHandler(w http.ResponseWriter, r *http.Request) {
+	webhookID, err := strconv.Atoi(chi.URLParam(r, "webhook_id"))
+	if err != nil || webhookID <= 0 {
+		logger.WarnContext(r.Context(), "Ошибка: некорректный идентификатор вебхука")
+		writeJSON(w, http.StatusBadRequest, map[string]any{"error": "Некорректный идентификатор вебхука"})
+		return
+	}
+
+	if _, err := api.Store.GetWebhook(r.Context(), webhookID); err != nil {
+		if errors.Is(err, database.ErrWebhookNotFound) {
+			logger.WarnContext(r.Context(), "Ошибка: вебхук %d не найден", webhookID)
+			writeJSON(w, http.StatusNotFound, map[string]any{"error": "Вебхук не найден"})
+			return
+		}
+		logger.ErrorContext(r.Context(), "Ошибка: не удалось получить вебхук: %v", err)
+		writeJSON(w, http.StatusInternalServerError, map[string]any{"error": "Не удалось получить историю доставок. Повторите попытку позже"})
+		return
+	}
+
+	page := 1
+	if p := r.URL.Query().Get("page"); p != "" {
+		if parsed, err := strconv.Atoi(p); err == nil && parsed > 0 {
+			page = parsed
+		}
+	}
+
+	limit := 20
+	offset := (page - 1) * limit
+
+	deliveries, err := api.Store.GetWebhookDeliveries(r.Context(), webhookID, limit, offset)
+	if err != nil {
+		logger.ErrorContext(r.Context(), "Ошибка: не удалось получить историю доставок вебхука: %v", err)
+		writeJSON(w, http.StatusInternalServerError, map[string]any{"error": "Не удалось получить историю доставок. Повторите попытку позже"})
+		return
+	}
+
+	if len(deliveries) == 0 {
+		writeJSON(w, http.StatusOK, map[string]any{"message": "Доставок не найдено"})
+		return
+	}
+
+	writeJSON(w, http.StatusOK, deliveries)
+	logger.InfoContext(r.Context(), "Выдана история доставок вебхука %d: page=%d count=%d", webhookID, page, len(deliveries))
+}
+
+// @Summary Отправить тестовое событие
+// @Description Ставит в очередь отправку тестового события webhook.test на вебхук
+// @Tags webhooks
+// @Produce json
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Param webhook_id path int true "Идентификатор вебхука"
+// @Success 202 {object} api.TestWebhookResponse "Тестовое событие поставлено в очередь"
+// @Failure 400 {object} api.ErrorResponse "Некорректный идентификатор вебхука"
+// @Failure 404 {object} api.ErrorResponse "Вебхук не найден"
+// @Failure 409 {object} api.ErrorResponse "Вебхук отключен"
+// @Failure 401 {object} api.ErrorResponse "Не указаны или недействительны учетные данные"
+// @Failure 403 {object} api.ErrorResponse "Недостаточно прав"
+// @Failure 429 {object} api.ErrorResponse "Превышен лимит запросов"
+// @Failure 500 {object} api.ErrorResponse "Внутренняя ошибка сервера"
+// @Router /webhooks/{webhook_id}/test [post]
+func (api *API) TestWebhookHandler(w http.ResponseWriter, r *http.Request) {
+	webhookID, err := strconv.Atoi(chi.URLParam(r, "webhook_id"))
+	if err != nil || webhookID <= 0 {
+		logger.WarnContext(r.Context(), "Ошибка: некорректный идентификатор вебхука")
+		writeJSON(w, http.StatusBadRequest, map[string]any{"error": "Некорректный идентификатор вебхука"})
+		return
+	}
+
+	deliveryID, err := api.Store.EnqueueTestWebhookEvent(r.Context(), webhookID)
+	if err != nil {
+		switch {
+		case errors.Is(err, database.ErrWebhookNotFound):
+			logger.WarnContext(r.Context(), "Ошибка: вебхук %d не найден", webhookID)
+			writeJSON(w, http.StatusNotFound, map[string]any{"error": "Вебхук не найден"})
+		case errors.Is(err, database.ErrWebhookDisabled):
+			logger.WarnContext(r.Context(), "Ошибка: вебхук %d отключен", webhookID)
+			writeJSON(w, http.StatusConflict, map[string]any{"error": "Вебхук отключен, включите его перед отправкой тестового события"})
+		default:
+			logger.ErrorContext(r.Context(), "Ошибка: не удалось поставить тестовое событие в очередь: %v", err)
+			writeJSON(w, http.StatusInternalServerError, map[string]any{"error": "Не удалось отправить тестовое событие. Повторите попытку позже"})
+		}
+		return
+	}
+
+	writeJSON(w, http.StatusAccepted, map[string]any{"message": "Тестовое событие поставлено в очередь на отправку", "delivery_id": deliveryID})
+	logger.InfoContext(r.Context(), "Тестовое событие для вебхука %d поставлено в очередь, доставка %d", webhookID, deliveryID)
+}
@@ -0,0 +1,165 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/Halturshik/EM-test-task/GO/database"
+	"github.com/Halturshik/EM-test-task/GO/logger"
+	"github.com/Halturshik/EM-test-task/config"
+)
+
+// webhookBatchSize — сколько доставок забирается за один проход. Доставки пачки
+// отправляются параллельно, поэтому все укладываются в аренду WEBHOOK_TIMEOUT*2.
+const webhookBatchSize = 20
+
+var (
+	webhookDispatcherCancel context.CancelFunc
+	webhookDispatcherDone   sync.WaitGroup
+)
+
+func StartWebhookDispatcher(store *database.Store, cfg *config.Config) {
+	ctx, cancel := context.WithCancel(context.Background())
+	webhookDispatcherCancel = cancel
+
+	client := &http.Client{
+		Timeout:   cfg.WebhookTimeout,
+		Transport: newWebhookTransport(cfg.WebhookTimeout),
+		// Подписанное тело не пересылается туда, куда перенаправляет получатель:
+		// ответ 3xx считается неудачной доставкой.
+		CheckRedirect: func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+
+	webhookDispatcherDone.Add(1)
+	go func() {
+		defer webhookDispatcherDone.Done()
+
+		ticker := time.NewTicker(cfg.WebhookPollInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ticker.C:
+				dispatchWebhooks(ctx, store, client, cfg)
+			case <-ctx.Done():
+				logger.Info("Фоновая отправка вебхуков остановлена")
+				return
+			}
+		}
+	}()
+}
+
+func StopWebhookDispatcher() {
+	if webhookDispatcherCancel != nil {
+		webhookDispatcherCancel()
+		webhookDispatcherDone.Wait()
+	}
+}
+
+func dispatchWebhooks(ctx context.Context, store *database.Store, client *http.Client, cfg *config.Config) {
+	if _, err := store.FanOutOutboxEvents(ctx, 100); err != nil {
+		logger.Error("Ошибка распределения событий по вебхукам: %v", err)
+		return
+	}
+
+	lease := cfg.WebhookTimeout * 2
+	deliveries, err := store.ClaimDueDeliveries(ctx, webhookBatchSize, lease)
+	if err != nil {
+		logger.Error("Ошибка выборки доставок вебхуков: %v", err)
+		return
+	}
+
+	var wg sync.WaitGroup
+	for _, d := range deliveries {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			deliverWebhook(ctx, store, client, cfg, d)
+		}()
+	}
+	wg.Wait()
+}
+
+func deliverWebhook(ctx context.Context, store *database.Store, client *http.Client, cfg *config.Config, d database.WebhookDelivery) {
+	statusCode, err := sendWebhook(ctx, client, d)
+	if err == nil {
+		if err := store.MarkDeliverySucceeded(ctx, d.ID, statusCode); err != nil {
+			logger.Error("Ошибка сохранения статуса доставки вебхука %d: %v", d.ID, err)
+		}
+		return
+	}
+
+	var code *int
+	if statusCode != 0 {
+		code = &statusCode
+	}
+
+	var nextAttemptAt *time.Time
+	if d.Attempts+1 < cfg.WebhookMaxAttempts {
+		next := time.Now().Add(webhookBackoff(d.Attempts, cfg.WebhookBackoffBase, cfg.WebhookBackoffMax))
+		nextAttemptAt = &next
+		logger.Warn("Не удалось доставить вебхук %d на %s (попытка %d): %v", d.ID, d.URL, d.Attempts+1, err)
+	} else {
+		logger.Error("Вебхук %d на %s перемещен в недоставленные после %d попыток: %v", d.ID, d.URL, d.Attempts+1, err)
+	}
+
+	if err := store.MarkDeliveryFailed(ctx, d.ID, code, err.Error(), nextAttemptAt); err != nil {
+		logger.Error("Ошибка сохранения статуса доставки вебхука %d: %v", d.ID, err)
+	}
+}
+
+func sendWebhook(ctx context.Context, client *http.Client, d database.WebhookDelivery) (int, error) {
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, d.URL, bytes.NewReader(d.Payload))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Webhook-Event", d.EventType)
+	req.Header.Set("X-Webhook-Delivery", strconv.FormatInt(d.ID, 10))
+	req.Header.Set("X-Webhook-Timestamp", timestamp)
+	req.Header.Set("X-Webhook-Signature", "sha256="+signWebhook(d.Secret, timestamp, d.Payload))
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return resp.StatusCode, fmt.Errorf("получатель ответил статусом %d", resp.StatusCode)
+	}
+
+	return resp.StatusCode, nil
+}
+
+func signWebhook(secret string, timestamp string, payload []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp + "."))
+	mac.Write(payload)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func webhookBackoff(attempts int, base time.Duration, maxDelay time.Duration) time.Duration {
+	delay := base
+	for i := 0; i < attempts && delay < maxDelay; i++ {
+		delay *= 2
+	}
+	if delay > maxDelay {
+		delay = maxDelay
+	}
+	return delay
+}
@@ -0,0 +1,45 @@
+package api
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSignWebhook(t *testing.T) {
+	payload := []byte(`{"event_type":"subscription.created"}`)
+	const want = "965562cb20c7cd9de191c84fd1bb37ab26f6bab938b1b91ec9bc6887a735bcad"
+
+	if got := signWebhook("whsec_test", "1700000000", payload); got != want {
+		t.Fatalf("signWebhook = %s, ожидалось %s", got, want)
+	}
+	if got := signWebhook("whsec_other", "1700000000", payload); got == want {
+		t.Fatal("подпись не зависит от секрета")
+	}
+	if got := signWebhook("whsec_test", "1700000001", payload); got == want {
+		t.Fatal("подпись не зависит от времени")
+	}
+}
+
+func TestWebhookBackoff(t *testing.T) {
+	base := 30 * time.Second
+	maxDelay := 6 * time.Hour
+
+	tests := []struct {
+		attempts int
+		want     time.Duration
+	}{
+		{attempts: 0, want: 30 * time.Second},
+		{attempts: 1, want: time.Minute},
+		{attempts: 2, want: 2 * time.Minute},
+		{attempts: 5, want: 16 * time.Minute},
+		{attempts: 9, want: 256 * time.Minute},
+		{attempts: 10, want: maxDelay},
+		{attempts: 1000, want: maxDelay},
+	}
+
+	for _, tt := range tests {
+		if got := webhookBackoff(tt.attempts, base, maxDelay); got != tt.want {
+			t.Errorf("webhookBackoff(%d) = %v, ожидалось %v", tt.attempts, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,91 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/netip"
+	"net/url"
+	"syscall"
+	"time"
+)
+
+var errWebhookAddressNotPublic = errors.New("адрес вебхука указывает на внутреннюю сеть")
+
+// nonPublicPrefixes — диапазоны, не покрытые методами netip.Addr, на которые
+// нельзя отправлять вебхуки.
+var nonPublicPrefixes = []netip.Prefix{
+	netip.MustParsePrefix("0.0.0.0/8"),
+	netip.MustParsePrefix("100.64.0.0/10"),
+	netip.MustParsePrefix("192.0.0.0/24"),
+	netip.MustParsePrefix("198.18.0.0/15"),
+	netip.MustParsePrefix("240.0.0.0/4"),
+	netip.MustParsePrefix("64:ff9b::/96"),
+}
+
+func publicIP(ip netip.Addr) bool {
+	ip = ip.Unmap()
+	if !ip.IsValid() || ip.IsUnspecified() || ip.IsLoopback() || ip.IsPrivate() ||
+		ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() || ip.IsInterfaceLocalMulticast() || ip.IsMulticast() {
+		return false
+	}
+	for _, prefix := range nonPublicPrefixes {
+		if prefix.Contains(ip) {
+			return false
+		}
+	}
+	return true
+}
+
+// checkWebhookHost разрешает имя узла и проверяет, что все его адреса публичные.
+func checkWebhookHost(ctx context.Context, host string) error {
+	if ip, err := netip.ParseAddr(host); err == nil {
+		if !publicIP(ip) {
+			return errWebhookAddressNotPublic
+		}
+		return nil
+	}
+
+	ips, err := net.DefaultResolver.LookupNetIP(ctx, "ip", host)
+	if err != nil {
+		return fmt.Errorf("не удалось разрешить адрес %s: %w", host, err)
+	}
+	for _, ip := range ips {
+		if !publicIP(ip) {
+			return errWebhookAddressNotPublic
+		}
+	}
+	return nil
+}
+
+func checkWebhookURLHost(ctx context.Context, raw string) error {
+	u, err := url.Parse(raw)
+	if err != nil {
+		return err
+	}
+	return checkWebhookHost(ctx, u.Hostname())
+}
+
+// webhookDialControl повторяет проверку для адреса, к которому действительно
+// выполняется подключение, чтобы ее нельзя было обойти подменой DNS-ответа.
+func webhookDialControl(network string, address string, _ syscall.RawConn) error {
+	addrPort, err := netip.ParseAddrPort(address)
+	if err != nil {
+		return err
+	}
+	if !publicIP(addrPort.Addr()) {
+		return errWebhookAddressNotPublic
+	}
+	return nil
+}
+
+func newWebhookTransport(timeout time.Duration) *http.Transport {
+	dialer := &net.Dialer{Timeout: timeout, Control: webhookDialControl}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = nil
+	transport.DialContext = dialer.DialContext
+	return transport
+}
@@ -0,0 +1,78 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"net/netip"
+	"testing"
+)
+
+func TestPublicIP(t *testing.T) {
+	tests := []struct {
+		addr string
+		want bool
+	}{
+		{addr: "8.8.8.8", want: true},
+		{addr: "93.184.216.34", want: true},
+		{addr: "2606:4700:4700::1111", want: true},
+		{addr: "127.0.0.1"},
+		{addr: "::1"},
+		{addr: "0.0.0.0"},
+		{addr: "::"},
+		{addr: "10.1.2.3"},
+		{addr: "172.16.0.1"},
+		{addr: "192.168.1.1"},
+		{addr: "169.254.169.254"},
+		{addr: "fe80::1"},
+		{addr: "fc00::1"},
+		{addr: "100.64.0.1"},
+		{addr: "198.18.0.1"},
+		{addr: "224.0.0.1"},
+		{addr: "255.255.255.255"},
+		{addr: "::ffff:127.0.0.1"},
+		{addr: "::ffff:169.254.169.254"},
+		{addr: "64:ff9b::a9fe:a9fe"},
+	}
+
+	for _, tt := range tests {
+		if got := publicIP(netip.MustParseAddr(tt.addr)); got != tt.want {
+			t.Errorf("publicIP(%s) = %v, ожидалось %v", tt.addr, got, tt.want)
+		}
+	}
+}
+
+func TestCheckWebhookURLHost(t *testing.T) {
+	tests := []struct {
+		url     string
+		wantErr bool
+	}{
+		{url: "https://8.8.8.8/hook"},
+		{url: "https://[2606:4700:4700::1111]:8443/hook"},
+		{url: "http://127.0.0.1:8080/hook", wantErr: true},
+		{url: "http://169.254.169.254/latest/meta-data", wantErr: true},
+		{url: "http://[::1]/hook", wantErr: true},
+		{url: "http://localhost/hook", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		err := checkWebhookURLHost(context.Background(), tt.url)
+		if tt.wantErr && err == nil {
+			t.Errorf("checkWebhookURLHost(%s): ожидалась ошибка", tt.url)
+		}
+		if !tt.wantErr && err != nil {
+			t.Errorf("checkWebhookURLHost(%s): неожиданная ошибка %v", tt.url, err)
+		}
+	}
+}
+
+func TestWebhookDialControl(t *testing.T) {
+	if err := webhookDialControl("tcp4", "8.8.8.8:443", nil); err != nil {
+		t.Fatalf("публичный адрес отклонен: %v", err)
+	}
+	if err := webhookDialControl("tcp4", "10.0.0.5:443", nil); !errors.Is(err, errWebhookAddressNotPublic) {
+		t.Fatalf("ожидалась errWebhookAddressNotPublic, получено %v", err)
+	}
+	if err := webhookDialControl("tcp6", "[::1]:80", nil); !errors.Is(err, errWebhookAddressNotPublic) {
+		t.Fatalf("ожидалась errWebhookAddressNotPublic, получено %v", err)
+	}
+}
@@ -0,0 +1,342 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"sort"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+func (s *Store) GetPlatformAnalytics(ctx context.Context, from, to time.Time) ([]MonthMetrics, error) {
+	from = time.Date(from.Year(), from.Month(), 1, 0, 0, 0, 0, time.UTC)
+	to = time.Date(to.Year(), to.Month(), 1, 0, 0, 0, 0, time.UTC)
+
+	now := time.Now()
+	currentMonth := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+
+	var result []MonthMetrics
+	for month := from; !month.After(to); month = month.AddDate(0, 1, 0) {
+		closed := month.Before(currentMonth)
+
+		if closed {
+			services, err := s.getCachedMonthMetrics(ctx, month)
+			if err != nil {
+				return nil, err
+			}
+			if services != nil {
+				result = append(result, MonthMetrics{Month: month, Cached: true, Services: services})
+				continue
+			}
+		}
+
+		services, err := s.computeMonthMetrics(ctx, month, closed)
+		if err != nil {
+			return nil, err
+		}
+
+		result = append(result, MonthMetrics{Month: month, Services: services})
+	}
+
+	return result, nil
+}
+
+func (s *Store) getCachedMonthMetrics(ctx context.Context, month time.Time) ([]ServiceMetrics, error) {
+	var raw []byte
+	query := `SELECT metrics FROM analytics_monthly_cache WHERE month = $1`
+	err := s.DB.QueryRowContext(ctx, query, month).Scan(&raw)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	services := []ServiceMetrics{}
+	if err := json.Unmarshal(raw, &services); err != nil {
+		return nil, err
+	}
+
+	return services, nil
+}
+
+// computeMonthMetrics считает метрики месяца в одном снимке REPEATABLE READ и,
+// если cache = true, сохраняет их в кэш в той же транзакции. Если за время
+// расчета изменение подписок успело сбросить кэш, результат не сохраняется:
+// иначе в кэше закрытого месяца остались бы устаревшие данные.
+func (s *Store) computeMonthMetrics(ctx context.Context, month time.Time, cache bool) ([]ServiceMetrics, error) {
+	tx, err := s.DB.BeginTx(ctx, &sql.TxOptions{Isolation: sql.LevelRepeatableRead})
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	var generation int64
+	if err := tx.QueryRowContext(ctx, `SELECT generation FROM analytics_cache_state`).Scan(&generation); err != nil {
+		return nil, err
+	}
+
+	services, err := queryMonthMetrics(ctx, tx, month)
+	if err != nil {
+		return nil, err
+	}
+	if !cache {
+		return services, nil
+	}
+
+	saved, err := saveMonthMetrics(ctx, tx, month, services, generation)
+	if err != nil {
+		return nil, err
+	}
+	if saved {
+		if err := tx.Commit(); err != nil && !isSerializationFailure(err) {
+			return nil, err
+		}
+	}
+
+	return services, nil
+}
+
+// saveMonthMetrics блокирует строку поколения и сохраняет метрики, только если
+// с момента снимка ни одно изменение подписок ее не обновило. В REPEATABLE READ
+// такое обновление приводит к ошибке сериализации, которая означает то же самое.
+func saveMonthMetrics(ctx context.Context, tx *sql.Tx, month time.Time, services []ServiceMetrics, generation int64) (bool, error) {
+	var current int64
+	err := tx.QueryRowContext(ctx, `SELECT generation FROM analytics_cache_state FOR SHARE`).Scan(&current)
+	if isSerializationFailure(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	if current != generation {
+		return false, nil
+	}
+
+	raw, err := json.Marshal(services)
+	if err != nil {
+		return false, err
+	}
+
+	query := `
+		INSERT INTO analytics_monthly_cache (month, metrics)
+		VALUES ($1, $2)
+		ON CONFLICT (month) DO UPDATE SET metrics = EXCLUDED.metrics, computed_at = NOW()
+	`
+	_, err = tx.ExecContext(ctx, query, month, string(raw))
+	if isSerializationFailure(err) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+func isSerializationFailure(err error) bool {
+	var pqErr *pq.Error
+	return errors.As(err, &pqErr) && pqErr.Code == "40001"
+}
+
+func queryMonthMetrics(ctx context.Context, tx *sql.Tx, month time.Time) ([]ServiceMetrics, error) {
+	monthEnd := endOfMonth(month)
+	byService := make(map[string]*ServiceMetrics)
+	get := func(name string) *ServiceMetrics {
+		m, ok := byService[name]
+		if !ok {
+			m = &ServiceMetrics{ServiceName: name, Tiers: []TierCount{}}
+			byService[name] = m
+		}
+		return m
+	}
+
+	tiersQuery := `
+		SELECT s.service_name, sp.price, COUNT(*)
+		FROM subscriptions s
+		JOIN LATERAL (
+			SELECT p.price
+			FROM subscription_prices p
+			WHERE p.subscription_id = s.id
+			  AND p.valid_from <= $2
+			  AND (p.valid_to IS NULL OR p.valid_to >= $1)
+			ORDER BY p.valid_from DESC
+			LIMIT 1
+		) sp ON TRUE
+		WHERE s.deleted_at IS NULL
+		  AND s.start_date <= $2
+		  AND (s.end_date IS NULL OR s.end_date >= $1)
+		GROUP BY s.service_name, sp.price
+		ORDER BY s.service_name, sp.price
+	`
+	rows, err := tx.QueryContext(ctx, tiersQuery, month, monthEnd)
+	if err != nil {
+		return nil, err
+	}
+	for rows.Next() {
+		var name string
+		var tier TierCount
+		if err := rows.Scan(&name, &tier.Price, &tier.Count); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		m := get(name)
+		m.Tiers = append(m.Tiers, tier)
+		m.Active += tier.Count
+		m.MRR += tier.Price * tier.Count
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	newQuery := `
+		SELECT service_name, COUNT(*)
+		FROM subscriptions
+		WHERE deleted_at IS NULL
+		  AND start_date BETWEEN $1 AND $2
+		GROUP BY service_name
+	`
+	rows, err = tx.QueryContext(ctx, newQuery, month, monthEnd)
+	if err != nil {
+		return nil, err
+	}
+	for rows.Next() {
+		var name string
+		var created int
+		if err := rows.Scan(&name, &created); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		get(name).New = created
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	// Отмененными считаются подписки, завершенные действием пользователя:
+	// закончившиеся в этом месяце после того, как пользователь установил или
+	// сократил дату окончания, и удаленные в этом месяце до своего окончания
+	// (если затем не восстановлены). Подписки, созданные сразу с датой окончания
+	// и закончившиеся в срок, в отмененные не попадают.
+	cancelledQuery := `
+		SELECT service_name, COUNT(*)
+		FROM (
+			SELECT s.service_name
+			FROM subscriptions s
+			JOIN LATERAL (
+				SELECT e.before_state->>'end_date' AS before_end, e.after_state->>'end_date' AS after_end
+				FROM subscription_events e
+				WHERE e.subscription_id = s.id
+				  AND e.event_type <> 'create'
+				  AND e.before_state->'end_date' IS DISTINCT FROM e.after_state->'end_date'
+				ORDER BY e.id DESC
+				LIMIT 1
+			) last_change ON TRUE
+			WHERE s.deleted_at IS NULL
+			  AND s.end_date BETWEEN $1 AND $2
+			  AND last_change.after_end IS NOT NULL
+			  AND (last_change.before_end IS NULL OR last_change.after_end::timestamptz < last_change.before_end::timestamptz)
+
+			UNION ALL
+
+			SELECT e.service_name
+			FROM subscription_events e
+			WHERE e.event_type = 'delete'
+			  AND e.created_at >= $1 AND e.created_at < $2::date + 1
+			  AND (e.before_state->>'end_date' IS NULL OR (e.before_state->>'end_date')::timestamptz >= $1)
+			  AND NOT EXISTS (
+				SELECT 1 FROM subscription_events r
+				WHERE r.subscription_id = e.subscription_id AND r.event_type = 'restore' AND r.id > e.id
+			  )
+		) cancelled
+		GROUP BY service_name
+	`
+	rows, err = tx.QueryContext(ctx, cancelledQuery, month, monthEnd)
+	if err != nil {
+		return nil, err
+	}
+	for rows.Next() {
+		var name string
+		var cancelled int
+		if err := rows.Scan(&name, &cancelled); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		get(name).Cancelled = cancelled
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	priceChangesQuery := `
+		SELECT s.service_name,
+			COUNT(*) FILTER (WHERE p.price > p.previous_price),
+			COUNT(*) FILTER (WHERE p.price < p.previous_price)
+		FROM subscription_prices p
+		JOIN subscriptions s ON s.id = p.subscription_id
+		WHERE s.deleted_at IS NULL
+		  AND p.previous_price IS NOT NULL
+		  AND p.valid_from BETWEEN $1 AND $2
+		GROUP BY s.service_name
+	`
+	rows, err = tx.QueryContext(ctx, priceChangesQuery, month, monthEnd)
+	if err != nil {
+		return nil, err
+	}
+	for rows.Next() {
+		var name string
+		var upgraded, downgraded int
+		if err := rows.Scan(&name, &upgraded, &downgraded); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		m := get(name)
+		m.Upgraded = upgraded
+		m.Downgraded = downgraded
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	result := make([]ServiceMetrics, 0, len(byService))
+	for _, m := range byService {
+		result = append(result, *m)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].ServiceName < result[j].ServiceName })
+
+	return result, nil
+}
+
+func invalidateAnalyticsCache(ctx context.Context, tx *sql.Tx, before, after *subSnapshot) error {
+	var since time.Time
+	for _, snap := range []*subSnapshot{before, after} {
+		if snap == nil {
+			continue
+		}
+		if since.IsZero() || snap.StartDate.Before(since) {
+			since = snap.StartDate
+		}
+		for _, p := range snap.Prices {
+			if p.ValidFrom.Before(since) {
+				since = p.ValidFrom
+			}
+		}
+	}
+	if since.IsZero() {
+		return nil
+	}
+
+	// Поколение обновляется до удаления: расчет, который уже держит блокировку
+	// строки, успеет сохранить результат и будет удален этим же DELETE, а
+	// начатый раньше расчет увидит новое поколение и не сохранит устаревшие данные.
+	if _, err := tx.ExecContext(ctx, `UPDATE analytics_cache_state SET generation = generation + 1`); err != nil {
+		return err
+	}
+
+	query := `DELETE FROM analytics_monthly_cache WHERE month >= date_trunc('month', $1::date)`
+	_, err := tx.ExecContext(ctx, query, since)
+	return err
+}
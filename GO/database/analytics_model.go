@@ -0,0 +1,25 @@
+package database
+
+import "time"
+
+type TierCount struct {
+	Price int `json:"price"`
+	Count int `json:"count"`
+}
+
+type ServiceMetrics struct {
+	ServiceName string      `json:"service_name"`
+	MRR         int         `json:"mrr"`
+	Active      int         `json:"active"`
+	New         int         `json:"new"`
+	Cancelled   int         `json:"cancelled"`
+	Upgraded    int         `json:"upgraded"`
+	Downgraded  int         `json:"downgraded"`
+	Tiers       []TierCount `json:"tiers"`
+}
+
+type MonthMetrics struct {
+	Month    time.Time
+	Cached   bool
+	Services []ServiceMetrics
+}
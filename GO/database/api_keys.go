@@ -0,0 +1,103 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+func (s *Store) CreateAPIKey(ctx context.Context, k *APIKey, keyHash string) error {
+	query := `
+		INSERT INTO api_keys (name, key_prefix, key_hash, scopes)
+		VALUES ($1, $2, $3, $4)
+		RETURNING id, created_at
+	`
+	return s.DB.QueryRowContext(ctx, query, k.Name, k.Prefix, keyHash, pq.Array(k.Scopes)).Scan(&k.ID, &k.CreatedAt)
+}
+
+func (s *Store) EnsureAPIKey(ctx context.Context, k *APIKey, keyHash string) error {
+	query := `
+		INSERT INTO api_keys (name, key_prefix, key_hash, scopes)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (key_hash) DO NOTHING
+	`
+	_, err := s.DB.ExecContext(ctx, query, k.Name, k.Prefix, keyHash, pq.Array(k.Scopes))
+	return err
+}
+
+func (s *Store) AuthenticateAPIKey(ctx context.Context, keyHash string) (*APIKey, error) {
+	var k APIKey
+	query := `
+		SELECT id, name, key_prefix, scopes, created_at, last_used_at, revoked_at
+		FROM api_keys
+		WHERE key_hash = $1 AND revoked_at IS NULL
+	`
+	err := s.DB.QueryRowContext(ctx, query, keyHash).Scan(&k.ID, &k.Name, &k.Prefix, pq.Array(&k.Scopes), &k.CreatedAt, &k.LastUsedAt, &k.RevokedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrAPIKeyNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	// last_used_at обновляется не чаще раза в минуту, чтобы параллельные запросы
+	// с одним ключом не ждали блокировку строки и не писали WAL на каждое чтение.
+	if k.LastUsedAt == nil || time.Since(*k.LastUsedAt) > time.Minute {
+		touchQuery := `
+			UPDATE api_keys
+			SET last_used_at = NOW()
+			WHERE id = $1 AND (last_used_at IS NULL OR last_used_at < NOW() - INTERVAL '1 minute')
+		`
+		if _, err := s.DB.ExecContext(ctx, touchQuery, k.ID); err != nil {
+			return nil, err
+		}
+	}
+
+	return &k, nil
+}
+
+func (s *Store) GetAPIKeys(ctx context.Context) ([]APIKey, error) {
+	query := `
+		SELECT id, name, key_prefix, scopes, created_at, last_used_at, revoked_at
+		FROM api_keys
+		ORDER BY id
+	`
+	rows, err := s.DB.QueryContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var result []APIKey
+	for rows.Next() {
+		var k APIKey
+		if err := rows.Scan(&k.ID, &k.Name, &k.Prefix, pq.Array(&k.Scopes), &k.CreatedAt, &k.LastUsedAt, &k.RevokedAt); err != nil {
+			return nil, err
+		}
+		result = append(result, k)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+func (s *Store) RevokeAPIKey(ctx context.Context, id int) error {
+	query := `UPDATE api_keys SET revoked_at = NOW() WHERE id = $1 AND revoked_at IS NULL`
+	res, err := s.DB.ExecContext(ctx, query, id)
+	if err != nil {
+		return err
+	}
+
+	rows, _ := res.RowsAffected()
+	if rows == 0 {
+		return ErrAPIKeyNotFound
+	}
+
+	return nil
+}
@@ -0,0 +1,26 @@
+package database
+
+import (
+	"errors"
+	"slices"
+	"time"
+)
+
+type APIKey struct {
+	ID         int        `json:"id"`
+	Name       string     `json:"name"`
+	Prefix     string     `json:"prefix"`
+	Scopes     []string   `json:"scopes"`
+	CreatedAt  time.Time  `json:"created_at"`
+	LastUsedAt *time.Time `json:"last_used_at,omitempty"`
+	RevokedAt  *time.Time `json:"revoked_at,omitempty"`
+}
+
+// APIKeyScopes — разрешения, которые можно выдать ключу. Scope admin
+// дает ключу все разрешения. Ключ не привязан к пользователю и всегда
+// работает с данными любого пользователя, поэтому users:any ему не выдается.
+var APIKeyScopes = append(slices.DeleteFunc(slices.Clone(Permissions), func(p string) bool {
+	return p == PermUsersAny
+}), RoleAdmin)
+
+var ErrAPIKeyNotFound = errors.New("API-ключ не найден")
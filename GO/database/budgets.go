@@ -0,0 +1,141 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const budgetStatusQuery = `
+	SELECT b.id, b.user_id, b.service_name, b.monthly_limit,
+		COALESCE((
+			SELECT SUM(sp.price)
+			FROM subscriptions s
+			JOIN subscription_prices sp ON sp.subscription_id = s.id
+			WHERE s.user_id = b.user_id
+			  AND s.deleted_at IS NULL
+			  AND (b.service_name IS NULL OR s.service_name = b.service_name)
+			  AND s.start_date <= $1
+			  AND (s.end_date IS NULL OR s.end_date >= $1)
+			  AND sp.valid_from <= $1
+			  AND (sp.valid_to IS NULL OR sp.valid_to >= $1)
+		), 0) AS projected
+	FROM budgets b
+`
+
+func (s *Store) SetBudget(ctx context.Context, userID uuid.UUID, serviceName *string, monthlyLimit int) error {
+	query := `
+		INSERT INTO budgets (user_id, service_name, monthly_limit)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (user_id, COALESCE(service_name, ''))
+		DO UPDATE SET monthly_limit = EXCLUDED.monthly_limit, last_alert_month = NULL, updated_at = NOW()
+	`
+	_, err := s.DB.ExecContext(ctx, query, userID, serviceName, monthlyLimit)
+	return err
+}
+
+func (s *Store) DeleteBudget(ctx context.Context, userID uuid.UUID, serviceName *string) error {
+	query := `DELETE FROM budgets WHERE user_id = $1 AND COALESCE(service_name, '') = COALESCE($2, '')`
+	res, err := s.DB.ExecContext(ctx, query, userID, serviceName)
+	if err != nil {
+		return err
+	}
+
+	rows, _ := res.RowsAffected()
+	if rows == 0 {
+		return ErrBudgetNotFound
+	}
+
+	return nil
+}
+
+func (s *Store) GetBudgetStatuses(ctx context.Context, userID uuid.UUID, month time.Time) ([]BudgetStatus, error) {
+	query := budgetStatusQuery + ` WHERE b.user_id = $2 ORDER BY b.service_name NULLS FIRST`
+	rows, err := s.DB.QueryContext(ctx, query, endOfMonth(month), userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return scanBudgetStatuses(rows)
+}
+
+func (s *Store) RecordBudgetAlerts(ctx context.Context, userID *uuid.UUID, month time.Time) ([]BudgetStatus, error) {
+	tx, err := s.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	monthStart := time.Date(month.Year(), month.Month(), 1, 0, 0, 0, 0, time.UTC)
+
+	query := budgetStatusQuery + ` WHERE (b.last_alert_month IS NULL OR b.last_alert_month <> $2)`
+	args := []any{endOfMonth(month), monthStart}
+	if userID != nil {
+		query += fmt.Sprintf(" AND b.user_id = $%d", len(args)+1)
+		args = append(args, *userID)
+	}
+	query += " FOR UPDATE OF b"
+
+	rows, err := tx.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	statuses, err := scanBudgetStatuses(rows)
+	rows.Close()
+	if err != nil {
+		return nil, err
+	}
+
+	var alerts []BudgetStatus
+	markQuery := `UPDATE budgets SET last_alert_month = $1 WHERE id = $2`
+	for _, b := range statuses {
+		if !b.Exceeded {
+			continue
+		}
+
+		if _, err := tx.ExecContext(ctx, markQuery, monthStart, b.ID); err != nil {
+			return nil, err
+		}
+
+		if _, err := enqueueOutboxEvent(ctx, tx, "budget.exceeded", map[string]any{
+			"user_id":                b.UserID,
+			"service_name":           b.ServiceName,
+			"month":                  monthStart.Format("01-2006"),
+			"monthly_limit":          b.MonthlyLimit,
+			"projected_monthly_cost": b.ProjectedMonthlyCost,
+		}); err != nil {
+			return nil, err
+		}
+
+		alerts = append(alerts, b)
+	}
+
+	return alerts, tx.Commit()
+}
+
+func scanBudgetStatuses(rows *sql.Rows) ([]BudgetStatus, error) {
+	var result []BudgetStatus
+	for rows.Next() {
+		var b BudgetStatus
+		if err := rows.Scan(&b.ID, &b.UserID, &b.ServiceName, &b.MonthlyLimit, &b.ProjectedMonthlyCost); err != nil {
+			return nil, err
+		}
+		b.Remaining = b.MonthlyLimit - b.ProjectedMonthlyCost
+		b.Exceeded = b.ProjectedMonthlyCost > b.MonthlyLimit
+		result = append(result, b)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+func endOfMonth(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month()+1, 0, 0, 0, 0, 0, time.UTC)
+}
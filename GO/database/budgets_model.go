@@ -0,0 +1,19 @@
+package database
+
+import (
+	"errors"
+
+	"github.com/google/uuid"
+)
+
+type BudgetStatus struct {
+	ID                   int       `json:"-"`
+	UserID               uuid.UUID `json:"-"`
+	ServiceName          *string   `json:"service_name,omitempty"`
+	MonthlyLimit         int       `json:"monthly_limit"`
+	ProjectedMonthlyCost int       `json:"projected_monthly_cost"`
+	Remaining            int       `json:"remaining"`
+	Exceeded             bool      `json:"exceeded"`
+}
+
+var ErrBudgetNotFound = errors.New("бюджет не найден")
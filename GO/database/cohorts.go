@@ -0,0 +1,58 @@
+package database
+
+import (
+	"context"
+	"time"
+)
+
+var CohortPeriods = []int{1, 3, 6, 12}
+
+type CohortRow struct {
+	Cohort      time.Time
+	ServiceName string
+	Size        int
+	Retained    []int
+}
+
+// GetCohortRetention группирует подписки по месяцу начала. Подписка считается
+// активной до end_date, а удаленная — до даты удаления, если та раньше: удаленные
+// подписки остаются в когорте как ушедшие. Приостановки подписок в сервисе нет,
+// поэтому удержание определяется только этими датами. Подписки, окончательно
+// стертые задачей очистки, в отчет не попадают.
+func (s *Store) GetCohortRetention(ctx context.Context, from, to time.Time, serviceName string) ([]CohortRow, error) {
+	query := `
+		SELECT date_trunc('month', start_date)::date AS cohort, service_name, COUNT(*),
+			COUNT(*) FILTER (WHERE active_until IS NULL OR active_until >= date_trunc('month', start_date) + INTERVAL '1 month'),
+			COUNT(*) FILTER (WHERE active_until IS NULL OR active_until >= date_trunc('month', start_date) + INTERVAL '3 months'),
+			COUNT(*) FILTER (WHERE active_until IS NULL OR active_until >= date_trunc('month', start_date) + INTERVAL '6 months'),
+			COUNT(*) FILTER (WHERE active_until IS NULL OR active_until >= date_trunc('month', start_date) + INTERVAL '12 months')
+		FROM (
+			SELECT start_date, service_name, LEAST(end_date, deleted_at::date) AS active_until
+			FROM subscriptions
+			WHERE start_date BETWEEN $1 AND $2
+			  AND ($3 = '' OR service_name = $3)
+		) s
+		GROUP BY cohort, service_name
+		ORDER BY cohort, service_name
+	`
+	rows, err := s.DB.QueryContext(ctx, query, from, endOfMonth(to), serviceName)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var result []CohortRow
+	for rows.Next() {
+		row := CohortRow{Retained: make([]int, len(CohortPeriods))}
+		if err := rows.Scan(&row.Cohort, &row.ServiceName, &row.Size, &row.Retained[0], &row.Retained[1], &row.Retained[2], &row.Retained[3]); err != nil {
+			return nil, err
+		}
+		result = append(result, row)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
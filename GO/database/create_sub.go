@@ -6,11 +6,6 @@ import (
 )
 
 func (s *Store) CreateSubscription(ctx context.Context, sub *Subs) error {
-	if sub.EndDate == nil {
-		t := time.Date(2099, 12, 31, 0, 0, 0, 0, time.UTC)
-		sub.EndDate = &t
-	}
-
 	tx, err := s.DB.BeginTx(ctx, nil)
 	if err != nil {
 		return err
@@ -25,7 +20,8 @@ func (s *Store) CreateSubscription(ctx context.Context, sub *Subs) error {
 			SELECT COUNT(1)
 			FROM subscriptions
 			WHERE user_id = $1 AND service_name = $2
-			  AND end_date >= CURRENT_DATE
+			  AND deleted_at IS NULL
+			  AND (end_date IS NULL OR end_date >= CURRENT_DATE)
 		`
 		err = tx.QueryRowContext(ctx, activeConflictQuery, sub.UserID, sub.ServiceName).Scan(&activeCount)
 		if err != nil {
@@ -41,7 +37,9 @@ func (s *Store) CreateSubscription(ctx context.Context, sub *Subs) error {
 		SELECT COUNT(1)
 		FROM subscriptions
 		WHERE user_id = $1 AND service_name = $2
-		  AND NOT ($3 > end_date OR $4 < start_date)
+		  AND deleted_at IS NULL
+		  AND (end_date IS NULL OR $3 <= end_date)
+		  AND ($4::date IS NULL OR $4 >= start_date)
 	`
 
 	err = tx.QueryRowContext(ctx, dateConflictQuery, sub.UserID, sub.ServiceName, sub.StartDate, sub.EndDate).Scan(&conflictCount)
@@ -73,6 +71,15 @@ func (s *Store) CreateSubscription(ctx context.Context, sub *Subs) error {
 	if err != nil {
 		return err
 	}
+
+	after, err := loadSubSnapshot(ctx, tx, subID)
+	if err != nil {
+		return err
+	}
+	if err := recordSubscriptionEvent(ctx, tx, "create", nil, after); err != nil {
+		return err
+	}
+
 	return tx.Commit()
 
 }
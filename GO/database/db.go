@@ -3,14 +3,20 @@ package database
 import (
 	"database/sql"
 	"fmt"
-	"log"
 
+	"github.com/Halturshik/EM-test-task/GO/logger"
 	"github.com/Halturshik/EM-test-task/config"
+	"github.com/XSAM/otelsql"
 	"github.com/pressly/goose"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
 )
 
+const MigrationsDir = "./GO/database/migrations"
+
 type Store struct {
 	DB *sql.DB
+
+	expectedMigration int64
 }
 
 func NewStore(db *sql.DB) *Store {
@@ -24,7 +30,7 @@ func ConnectDB(cfg *config.Config) (*sql.DB, error) {
 		cfg.DBHost, cfg.DBPort, cfg.DBUser, cfg.DBPassword, cfg.DBName,
 	)
 
-	db, err := sql.Open("postgres", connectionStr)
+	db, err := otelsql.Open("postgres", connectionStr, otelsql.WithAttributes(semconv.DBSystemPostgreSQL))
 	if err != nil {
 		return nil, fmt.Errorf("ошибка подключения к БД: %w", err)
 	}
@@ -33,19 +39,17 @@ func ConnectDB(cfg *config.Config) (*sql.DB, error) {
 		return nil, fmt.Errorf("не удалось соединиться с БД: %w", err)
 	}
 
-	log.Println("Соединение с PostgreSQL установлено")
+	logger.Info("Соединение с PostgreSQL установлено")
 
 	if err := goose.SetDialect("postgres"); err != nil {
 		return nil, fmt.Errorf("ошибка установки диалекта goose: %w", err)
 	}
 
-	migrationsDir := "./GO/database/migrations"
-
-	if err := goose.Up(db, migrationsDir); err != nil {
+	if err := goose.Up(db, MigrationsDir); err != nil {
 		return nil, fmt.Errorf("ошибка при применении миграций: %w", err)
 	}
 
-	log.Println("Миграции успешно применены")
+	logger.Info("Миграции успешно применены")
 
 	return db, nil
 
@@ -9,7 +9,7 @@ import (
 	"github.com/google/uuid"
 )
 
-func (s *Store) DeleteSubscription(ctx context.Context, userID uuid.UUID, serviceName string, startDate time.Time) error {
+func (s *Store) DeleteSubscription(ctx context.Context, userID uuid.UUID, serviceName string, startDate time.Time, expectedVersion *int64) error {
 	tx, err := s.DB.BeginTx(ctx, nil)
 	if err != nil {
 		return err
@@ -17,32 +17,36 @@ func (s *Store) DeleteSubscription(ctx context.Context, userID uuid.UUID, servic
 	defer tx.Rollback()
 
 	syncQuery := ` 
-	SELECT id FROM subscriptions 
-	WHERE user_id=$1 AND service_name=$2 AND start_date=$3
+	SELECT id, version FROM subscriptions 
+	WHERE user_id=$1 AND service_name=$2 AND start_date=$3 AND deleted_at IS NULL
+	FOR UPDATE
 	`
 
-	deletePricesQuery := `
-		DELETE FROM subscription_prices 
-		WHERE subscription_id=$1
-	`
-	deleteSubQuery := `
-		DELETE FROM subscriptions 
+	softDeleteQuery := `
+		UPDATE subscriptions
+		SET deleted_at = NOW(), version = nextval('subscription_version_seq')
 		WHERE id=$1
 	`
 
 	var subID int
-	if err := tx.QueryRowContext(ctx, syncQuery, userID, serviceName, startDate).Scan(&subID); err != nil {
+	var version int64
+	if err := tx.QueryRowContext(ctx, syncQuery, userID, serviceName, startDate).Scan(&subID, &version); err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			return ErrSubNotFound
 		}
 		return err
 	}
 
-	if _, err := tx.ExecContext(ctx, deletePricesQuery, subID); err != nil {
+	if expectedVersion != nil && *expectedVersion != version {
+		return ErrSubVersionMismatch
+	}
+
+	before, err := loadSubSnapshot(ctx, tx, subID)
+	if err != nil {
 		return err
 	}
 
-	res, err := tx.ExecContext(ctx, deleteSubQuery, subID)
+	res, err := tx.ExecContext(ctx, softDeleteQuery, subID)
 	if err != nil {
 		return err
 	}
@@ -52,5 +56,134 @@ func (s *Store) DeleteSubscription(ctx context.Context, userID uuid.UUID, servic
 		return ErrSubNotFound
 	}
 
+	after, err := loadSubSnapshot(ctx, tx, subID)
+	if err != nil {
+		return err
+	}
+	if err := recordSubscriptionEvent(ctx, tx, "delete", before, after); err != nil {
+		return err
+	}
+
 	return tx.Commit()
 }
+
+func (s *Store) RestoreSubscription(ctx context.Context, userID uuid.UUID, serviceName string, startDate time.Time) error {
+	tx, err := s.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var subID int
+	var endDate *time.Time
+	deletedQuery := `
+		SELECT id, end_date FROM subscriptions
+		WHERE user_id=$1 AND service_name=$2 AND start_date=$3 AND deleted_at IS NOT NULL
+		ORDER BY deleted_at DESC
+		LIMIT 1
+		FOR UPDATE
+	`
+	if err := tx.QueryRowContext(ctx, deletedQuery, userID, serviceName, startDate).Scan(&subID, &endDate); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrSubNotFound
+		}
+		return err
+	}
+
+	var conflictCount int
+	conflictQuery := `
+		SELECT COUNT(1)
+		FROM subscriptions
+		WHERE user_id = $1 AND service_name = $2
+		  AND deleted_at IS NULL
+		  AND (end_date IS NULL OR $3 <= end_date)
+		  AND ($4::date IS NULL OR $4 >= start_date)
+	`
+	if err := tx.QueryRowContext(ctx, conflictQuery, userID, serviceName, startDate, endDate).Scan(&conflictCount); err != nil {
+		return err
+	}
+	if conflictCount > 0 {
+		return ErrSubOverlapExist
+	}
+
+	before, err := loadSubSnapshot(ctx, tx, subID)
+	if err != nil {
+		return err
+	}
+
+	restoreQuery := `
+		UPDATE subscriptions
+		SET deleted_at = NULL, version = nextval('subscription_version_seq')
+		WHERE id=$1
+	`
+	if _, err := tx.ExecContext(ctx, restoreQuery, subID); err != nil {
+		return err
+	}
+
+	after, err := loadSubSnapshot(ctx, tx, subID)
+	if err != nil {
+		return err
+	}
+	if err := recordSubscriptionEvent(ctx, tx, "restore", before, after); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// PurgeDeletedSubscriptions окончательно удаляет подписки, удаленные раньше
+// retention. Удаление записывается в журнал событий от имени system:purge.
+func (s *Store) PurgeDeletedSubscriptions(ctx context.Context, retention time.Duration) (int64, error) {
+	ctx = WithAuditInfo(ctx, AuditInfo{Actor: "system:purge"})
+
+	tx, err := s.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	expiredQuery := `
+		SELECT id FROM subscriptions
+		WHERE deleted_at < NOW() - $1 * INTERVAL '1 second'
+		ORDER BY id
+		FOR UPDATE
+	`
+	rows, err := tx.QueryContext(ctx, expiredQuery, retention.Seconds())
+	if err != nil {
+		return 0, err
+	}
+	var ids []int
+	for rows.Next() {
+		var id int
+		if err := rows.Scan(&id); err != nil {
+			rows.Close()
+			return 0, err
+		}
+		ids = append(ids, id)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, err
+	}
+
+	deletePricesQuery := `DELETE FROM subscription_prices WHERE subscription_id = $1`
+	deleteSubQuery := `DELETE FROM subscriptions WHERE id = $1`
+
+	for _, id := range ids {
+		before, err := loadSubSnapshot(ctx, tx, id)
+		if err != nil {
+			return 0, err
+		}
+		if _, err := tx.ExecContext(ctx, deletePricesQuery, id); err != nil {
+			return 0, err
+		}
+		if _, err := tx.ExecContext(ctx, deleteSubQuery, id); err != nil {
+			return 0, err
+		}
+		if err := recordSubscriptionEvent(ctx, tx, "purge", before, nil); err != nil {
+			return 0, err
+		}
+	}
+
+	return int64(len(ids)), tx.Commit()
+}
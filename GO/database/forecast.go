@@ -0,0 +1,39 @@
+package database
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type ForecastMonth struct {
+	Month     time.Time
+	Committed int
+	Projected int
+}
+
+func (s *Store) ForecastSubscriptionCost(ctx context.Context, userID uuid.UUID, serviceName string, from time.Time, months int) ([]ForecastMonth, error) {
+	from = time.Date(from.Year(), from.Month(), 1, 0, 0, 0, 0, time.UTC)
+	to := endOfMonth(from.AddDate(0, months-1, 0))
+
+	segments, err := s.getPriceSegments(ctx, userID, serviceName, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]ForecastMonth, months)
+	for i := range result {
+		result[i].Month = from.AddDate(0, i, 0)
+	}
+
+	eachSegmentMonth(segments, from, months, func(i int, _ time.Time, seg PriceSegment) {
+		if seg.SubEndDate != nil {
+			result[i].Committed += seg.Price
+		} else {
+			result[i].Projected += seg.Price
+		}
+	})
+
+	return result, nil
+}
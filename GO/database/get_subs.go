@@ -10,9 +10,9 @@ import (
 
 func (s *Store) GetSubscriptions(ctx context.Context, userID uuid.UUID, serviceName string, status string, limit int, offset int) ([]Subs, error) {
 	query := `
-        SELECT id, user_id, service_name, price, start_date, end_date 
+        SELECT id, user_id, service_name, price, start_date, end_date, version
         FROM subscriptions 
-        WHERE user_id = $1 
+        WHERE user_id = $1 AND deleted_at IS NULL
     `
 	args := []any{userID}
 
@@ -22,13 +22,13 @@ func (s *Store) GetSubscriptions(ctx context.Context, userID uuid.UUID, serviceN
 	}
 
 	if status == "active" {
-		query += " AND end_date >= NOW()"
+		query += " AND (end_date IS NULL OR end_date >= NOW())"
 	} else {
 		query += " AND end_date < NOW()"
 	}
 
 	if status == "active" {
-		query += " ORDER BY end_date ASC"
+		query += " ORDER BY end_date ASC NULLS LAST"
 	} else {
 		query += " ORDER BY end_date DESC"
 	}
@@ -49,7 +49,7 @@ func (s *Store) GetSubscriptions(ctx context.Context, userID uuid.UUID, serviceN
 	for rows.Next() {
 		var s Subs
 		if err := rows.Scan(
-			&s.ID, &s.UserID, &s.ServiceName, &s.Price, &s.StartDate, &s.EndDate,
+			&s.ID, &s.UserID, &s.ServiceName, &s.Price, &s.StartDate, &s.EndDate, &s.Version,
 		); err != nil {
 			return nil, err
 		}
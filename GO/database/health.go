@@ -0,0 +1,47 @@
+package database
+
+import (
+	"context"
+	"math"
+
+	"github.com/pressly/goose"
+)
+
+func (s *Store) Ping(ctx context.Context) error {
+	return s.DB.PingContext(ctx)
+}
+
+// LoadExpectedMigrationVersion один раз при запуске запоминает номер последней
+// миграции из каталога MigrationsDir для проверки готовности.
+func (s *Store) LoadExpectedMigrationVersion() error {
+	migrations, err := goose.CollectMigrations(MigrationsDir, 0, math.MaxInt64)
+	if err != nil {
+		return err
+	}
+
+	last, err := migrations.Last()
+	if err != nil {
+		return err
+	}
+
+	s.expectedMigration = last.Version
+	return nil
+}
+
+func (s *Store) GetMigrationVersions(ctx context.Context) (int64, int64, error) {
+	var current int64
+	query := `
+		SELECT COALESCE(MAX(version_id), 0)
+		FROM (
+			SELECT DISTINCT ON (version_id) version_id, is_applied
+			FROM goose_db_version
+			ORDER BY version_id, id DESC
+		) v
+		WHERE is_applied
+	`
+	if err := s.DB.QueryRowContext(ctx, query).Scan(&current); err != nil {
+		return 0, 0, err
+	}
+
+	return current, s.expectedMigration, nil
+}
@@ -0,0 +1,85 @@
+package database
+
+import (
+	"context"
+	"time"
+)
+
+type IdempotencyRecord struct {
+	Owner       string
+	Key         string
+	RequestHash string
+	StatusCode  *int
+	ContentType string
+	Body        []byte
+	ExpiresAt   time.Time
+}
+
+func (s *Store) ReserveIdempotencyKey(ctx context.Context, owner string, key string, requestHash string, ttl time.Duration) (*IdempotencyRecord, error) {
+	tx, err := s.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	insertQuery := `
+		INSERT INTO idempotency_keys (owner, key, request_hash, expires_at)
+		VALUES ($1, $2, $3, NOW() + $4 * INTERVAL '1 second')
+		ON CONFLICT (owner, key) DO UPDATE
+		SET request_hash = EXCLUDED.request_hash, status_code = NULL, content_type = NULL,
+			response_body = NULL, expires_at = EXCLUDED.expires_at
+		WHERE idempotency_keys.expires_at < NOW()
+	`
+	res, err := tx.ExecContext(ctx, insertQuery, owner, key, requestHash, ttl.Seconds())
+	if err != nil {
+		return nil, err
+	}
+
+	rows, _ := res.RowsAffected()
+	if rows == 1 {
+		return nil, tx.Commit()
+	}
+
+	rec := &IdempotencyRecord{Owner: owner, Key: key}
+	var contentType *string
+	selectQuery := `
+		SELECT request_hash, status_code, content_type, response_body, expires_at
+		FROM idempotency_keys
+		WHERE owner = $1 AND key = $2
+	`
+	if err := tx.QueryRowContext(ctx, selectQuery, owner, key).Scan(
+		&rec.RequestHash, &rec.StatusCode, &contentType, &rec.Body, &rec.ExpiresAt,
+	); err != nil {
+		return nil, err
+	}
+	if contentType != nil {
+		rec.ContentType = *contentType
+	}
+
+	return rec, tx.Commit()
+}
+
+func (s *Store) SaveIdempotencyResponse(ctx context.Context, owner string, key string, statusCode int, contentType string, body []byte) error {
+	query := `
+		UPDATE idempotency_keys
+		SET status_code = $1, content_type = $2, response_body = $3
+		WHERE owner = $4 AND key = $5
+	`
+	_, err := s.DB.ExecContext(ctx, query, statusCode, contentType, body, owner, key)
+	return err
+}
+
+func (s *Store) DeleteIdempotencyKey(ctx context.Context, owner string, key string) error {
+	query := `DELETE FROM idempotency_keys WHERE owner = $1 AND key = $2`
+	_, err := s.DB.ExecContext(ctx, query, owner, key)
+	return err
+}
+
+func (s *Store) PurgeExpiredIdempotencyKeys(ctx context.Context) (int64, error) {
+	query := `DELETE FROM idempotency_keys WHERE expires_at < NOW()`
+	res, err := s.DB.ExecContext(ctx, query)
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
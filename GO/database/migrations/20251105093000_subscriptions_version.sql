@@ -0,0 +1,17 @@
+-- +goose Up
+-- +goose StatementBegin
+SELECT 'up SQL query';
+-- +goose StatementEnd
+
+CREATE SEQUENCE subscription_version_seq;
+
+ALTER TABLE subscriptions
+ADD COLUMN version BIGINT NOT NULL DEFAULT nextval('subscription_version_seq');
+
+-- +goose Down
+-- +goose StatementBegin
+SELECT 'down SQL query';
+-- +goose StatementEnd
+
+ALTER TABLE subscriptions DROP COLUMN IF EXISTS version;
+DROP SEQUENCE IF EXISTS subscription_version_seq;
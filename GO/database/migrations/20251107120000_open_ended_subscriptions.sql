@@ -0,0 +1,15 @@
+-- +goose Up
+-- +goose StatementBegin
+SELECT 'up SQL query';
+-- +goose StatementEnd
+
+UPDATE subscriptions SET end_date = NULL WHERE end_date = DATE '2099-12-31';
+UPDATE subscription_prices SET valid_to = NULL WHERE valid_to = DATE '2099-12-31';
+
+-- +goose Down
+-- +goose StatementBegin
+SELECT 'down SQL query';
+-- +goose StatementEnd
+
+UPDATE subscription_prices SET valid_to = DATE '2099-12-31' WHERE valid_to IS NULL;
+UPDATE subscriptions SET end_date = DATE '2099-12-31' WHERE end_date IS NULL;
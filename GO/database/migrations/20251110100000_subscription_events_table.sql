@@ -0,0 +1,41 @@
+-- +goose Up
+-- +goose StatementBegin
+SELECT 'up SQL query';
+-- +goose StatementEnd
+
+CREATE TABLE subscription_events (
+    id BIGSERIAL PRIMARY KEY,
+    subscription_id INT NOT NULL,
+    user_id UUID NOT NULL,
+    service_name VARCHAR(64) NOT NULL,
+    event_type VARCHAR(32) NOT NULL,
+    actor VARCHAR(255) NOT NULL,
+    request_id VARCHAR(255) NULL,
+    before_state JSONB NULL,
+    after_state JSONB NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_subscription_events_user_service
+ON subscription_events(user_id, service_name, id);
+
+-- +goose StatementBegin
+CREATE FUNCTION forbid_subscription_events_change() RETURNS trigger AS $$
+BEGIN
+    RAISE EXCEPTION 'subscription_events is append-only';
+END;
+$$ LANGUAGE plpgsql;
+-- +goose StatementEnd
+
+CREATE TRIGGER subscription_events_append_only
+BEFORE UPDATE OR DELETE ON subscription_events
+FOR EACH ROW EXECUTE FUNCTION forbid_subscription_events_change();
+
+-- +goose Down
+-- +goose StatementBegin
+SELECT 'down SQL query';
+-- +goose StatementEnd
+
+DROP TRIGGER IF EXISTS subscription_events_append_only ON subscription_events;
+DROP FUNCTION IF EXISTS forbid_subscription_events_change();
+DROP TABLE IF EXISTS subscription_events;
@@ -0,0 +1,33 @@
+-- +goose Up
+-- +goose StatementBegin
+SELECT 'up SQL query';
+-- +goose StatementEnd
+
+ALTER TABLE subscriptions ADD COLUMN deleted_at TIMESTAMPTZ NULL;
+
+DROP INDEX IF EXISTS idx_unique_subscription;
+
+CREATE UNIQUE INDEX idx_unique_subscription
+ON subscriptions(user_id, service_name, start_date)
+WHERE deleted_at IS NULL;
+
+CREATE INDEX idx_subscriptions_deleted_at
+ON subscriptions(deleted_at)
+WHERE deleted_at IS NOT NULL;
+
+-- +goose Down
+-- +goose StatementBegin
+SELECT 'down SQL query';
+-- +goose StatementEnd
+
+DELETE FROM subscription_prices
+WHERE subscription_id IN (SELECT id FROM subscriptions WHERE deleted_at IS NOT NULL);
+DELETE FROM subscriptions WHERE deleted_at IS NOT NULL;
+
+DROP INDEX IF EXISTS idx_subscriptions_deleted_at;
+DROP INDEX IF EXISTS idx_unique_subscription;
+
+CREATE UNIQUE INDEX idx_unique_subscription
+ON subscriptions(user_id, service_name, start_date);
+
+ALTER TABLE subscriptions DROP COLUMN IF EXISTS deleted_at;
@@ -0,0 +1,29 @@
+-- +goose Up
+-- +goose StatementBegin
+SELECT 'up SQL query';
+-- +goose StatementEnd
+
+CREATE TABLE user_contacts (
+    user_id UUID PRIMARY KEY,
+    email VARCHAR(255) NOT NULL,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE TABLE sent_reminders (
+    id SERIAL PRIMARY KEY,
+    subscription_id INT NOT NULL REFERENCES subscriptions(id) ON DELETE CASCADE,
+    kind VARCHAR(16) NOT NULL,
+    target_date DATE NOT NULL,
+    sent_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE UNIQUE INDEX idx_sent_reminders_unique
+ON sent_reminders(subscription_id, kind, target_date);
+
+-- +goose Down
+-- +goose StatementBegin
+SELECT 'down SQL query';
+-- +goose StatementEnd
+
+DROP TABLE IF EXISTS sent_reminders;
+DROP TABLE IF EXISTS user_contacts;
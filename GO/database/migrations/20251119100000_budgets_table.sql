@@ -0,0 +1,25 @@
+-- +goose Up
+-- +goose StatementBegin
+SELECT 'up SQL query';
+-- +goose StatementEnd
+
+CREATE TABLE budgets (
+    id SERIAL PRIMARY KEY,
+    user_id UUID NOT NULL,
+    service_name VARCHAR(64) NULL,
+    monthly_limit INT NOT NULL CHECK (monthly_limit > 0),
+    last_alert_month DATE NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE UNIQUE INDEX idx_budgets_user_service
+ON budgets(user_id, COALESCE(service_name, ''));
+
+-- +goose Down
+-- +goose StatementBegin
+SELECT 'down SQL query';
+-- +goose StatementEnd
+
+DROP INDEX IF EXISTS idx_budgets_user_service;
+DROP TABLE IF EXISTS budgets;
@@ -0,0 +1,41 @@
+-- +goose Up
+-- +goose StatementBegin
+SELECT 'up SQL query';
+-- +goose StatementEnd
+
+CREATE TABLE role_permissions (
+    role VARCHAR(32) NOT NULL,
+    permission VARCHAR(64) NOT NULL,
+    PRIMARY KEY (role, permission)
+);
+
+INSERT INTO role_permissions (role, permission) VALUES
+    ('user', 'subscriptions:read'),
+    ('user', 'subscriptions:write'),
+    ('user', 'reports:read'),
+    ('support', 'subscriptions:read'),
+    ('support', 'users:any'),
+    ('finance', 'reports:read'),
+    ('finance', 'analytics:read'),
+    ('finance', 'users:any'),
+    ('admin', 'subscriptions:read'),
+    ('admin', 'subscriptions:write'),
+    ('admin', 'subscriptions:restore'),
+    ('admin', 'reports:read'),
+    ('admin', 'analytics:read'),
+    ('admin', 'webhooks:manage'),
+    ('admin', 'api_keys:manage'),
+    ('admin', 'users:any');
+
+UPDATE api_keys
+SET scopes = array_append(scopes, 'reports:read')
+WHERE 'subscriptions:read' = ANY(scopes) AND NOT 'reports:read' = ANY(scopes);
+
+-- +goose Down
+-- +goose StatementBegin
+SELECT 'down SQL query';
+-- +goose StatementEnd
+
+-- Разрешение reports:read, добавленное ключам в Up, не снимается: по данным
+-- нельзя отличить его от выданного при выпуске ключа позже.
+DROP TABLE IF EXISTS role_permissions;
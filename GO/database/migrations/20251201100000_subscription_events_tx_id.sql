@@ -0,0 +1,21 @@
+-- +goose Up
+-- +goose StatementBegin
+SELECT 'up SQL query';
+-- +goose StatementEnd
+
+-- Транзакция, записавшая событие. Идентификаторы BIGSERIAL выдаются до фиксации
+-- и могут стать видимыми не по порядку, поэтому поток событий читает только
+-- записи завершенных транзакций в порядке (tx_id, id).
+ALTER TABLE subscription_events
+ADD COLUMN tx_id XID8 NOT NULL DEFAULT pg_current_xact_id();
+
+CREATE INDEX idx_subscription_events_tx_id
+ON subscription_events(tx_id, id);
+
+-- +goose Down
+-- +goose StatementBegin
+SELECT 'down SQL query';
+-- +goose StatementEnd
+
+DROP INDEX IF EXISTS idx_subscription_events_tx_id;
+ALTER TABLE subscription_events DROP COLUMN IF EXISTS tx_id;
@@ -0,0 +1,14 @@
+-- +goose Up
+-- +goose StatementBegin
+SELECT 'up SQL query';
+-- +goose StatementEnd
+
+-- Изменился расчет отмененных подписок: закэшированные месяцы пересчитываются.
+DELETE FROM analytics_monthly_cache;
+
+-- +goose Down
+-- +goose StatementBegin
+SELECT 'down SQL query';
+-- +goose StatementEnd
+
+DELETE FROM analytics_monthly_cache;
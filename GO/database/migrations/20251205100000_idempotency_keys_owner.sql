@@ -0,0 +1,23 @@
+-- +goose Up
+-- +goose StatementBegin
+SELECT 'up SQL query';
+-- +goose StatementEnd
+
+-- Ключи идемпотентности уникальны в пределах вызывающего (API-ключа или
+-- пользователя JWT): один и тот же Idempotency-Key разных клиентов не пересекается.
+DELETE FROM idempotency_keys;
+
+ALTER TABLE idempotency_keys DROP CONSTRAINT idempotency_keys_pkey;
+ALTER TABLE idempotency_keys ADD COLUMN owner VARCHAR(255) NOT NULL;
+ALTER TABLE idempotency_keys ADD PRIMARY KEY (owner, key);
+
+-- +goose Down
+-- +goose StatementBegin
+SELECT 'down SQL query';
+-- +goose StatementEnd
+
+DELETE FROM idempotency_keys;
+
+ALTER TABLE idempotency_keys DROP CONSTRAINT idempotency_keys_pkey;
+ALTER TABLE idempotency_keys DROP COLUMN IF EXISTS owner;
+ALTER TABLE idempotency_keys ADD PRIMARY KEY (key);
@@ -0,0 +1,20 @@
+-- +goose Up
+-- +goose StatementBegin
+SELECT 'up SQL query';
+-- +goose StatementEnd
+
+-- Поколение кэша аналитики: каждое изменение подписок увеличивает его, а
+-- расчет месяца сохраняет результат, только если поколение не изменилось.
+CREATE TABLE analytics_cache_state (
+    id BOOLEAN PRIMARY KEY DEFAULT TRUE CHECK (id),
+    generation BIGINT NOT NULL DEFAULT 0
+);
+
+INSERT INTO analytics_cache_state (id) VALUES (TRUE);
+
+-- +goose Down
+-- +goose StatementBegin
+SELECT 'down SQL query';
+-- +goose StatementEnd
+
+DROP TABLE IF EXISTS analytics_cache_state;
@@ -0,0 +1,25 @@
+-- +goose Up
+-- +goose StatementBegin
+SELECT 'up SQL query';
+-- +goose StatementEnd
+
+-- Индексы для фоновой очистки завершенных доставок и разосланных событий outbox.
+CREATE INDEX idx_webhook_deliveries_outbox_event
+ON webhook_deliveries(outbox_event_id);
+
+CREATE INDEX idx_webhook_deliveries_finished
+ON webhook_deliveries(finished_at)
+WHERE status IN ('delivered', 'dead');
+
+CREATE INDEX idx_outbox_events_dispatched
+ON outbox_events(dispatched_at)
+WHERE dispatched_at IS NOT NULL;
+
+-- +goose Down
+-- +goose StatementBegin
+SELECT 'down SQL query';
+-- +goose StatementEnd
+
+DROP INDEX IF EXISTS idx_outbox_events_dispatched;
+DROP INDEX IF EXISTS idx_webhook_deliveries_finished;
+DROP INDEX IF EXISTS idx_webhook_deliveries_outbox_event;
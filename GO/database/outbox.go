@@ -0,0 +1,243 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var outboxEventTypes = map[string]string{
+	"create":            "subscription.created",
+	"upgrade":           "subscription.upgraded",
+	"downgrade":         "subscription.downgrade_scheduled",
+	"rollback":          "subscription.rolled_back",
+	"date_change":       "subscription.updated",
+	"start_date_change": "subscription.updated",
+	"delete":            "subscription.deleted",
+	"restore":           "subscription.restored",
+	"price_sync":        "subscription.price_changed",
+	"purge":             "subscription.purged",
+}
+
+type outboxPayload struct {
+	EventID    uuid.UUID `json:"event_id"`
+	EventType  string    `json:"event_type"`
+	OccurredAt time.Time `json:"occurred_at"`
+	Data       any       `json:"data"`
+}
+
+func enqueueOutboxEvent(ctx context.Context, tx *sql.Tx, eventType string, data any) (int64, error) {
+	payload, err := json.Marshal(outboxPayload{
+		EventID:    uuid.New(),
+		EventType:  eventType,
+		OccurredAt: time.Now().UTC(),
+		Data:       data,
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	var id int64
+	query := `INSERT INTO outbox_events (event_type, payload) VALUES ($1, $2) RETURNING id`
+	err = tx.QueryRowContext(ctx, query, eventType, string(payload)).Scan(&id)
+	return id, err
+}
+
+func (s *Store) FanOutOutboxEvents(ctx context.Context, limit int) (int, error) {
+	tx, err := s.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	pendingQuery := `
+		SELECT id, event_type
+		FROM outbox_events
+		WHERE dispatched_at IS NULL
+		ORDER BY id
+		LIMIT $1
+		FOR UPDATE SKIP LOCKED
+	`
+	rows, err := tx.QueryContext(ctx, pendingQuery, limit)
+	if err != nil {
+		return 0, err
+	}
+
+	type pendingEvent struct {
+		id        int64
+		eventType string
+	}
+	var pending []pendingEvent
+	for rows.Next() {
+		var e pendingEvent
+		if err := rows.Scan(&e.id, &e.eventType); err != nil {
+			rows.Close()
+			return 0, err
+		}
+		pending = append(pending, e)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, err
+	}
+
+	fanOutQuery := `
+		INSERT INTO webhook_deliveries (webhook_id, outbox_event_id)
+		SELECT id, $1 FROM webhooks
+		WHERE enabled AND (cardinality(event_types) = 0 OR $2 = ANY(event_types))
+	`
+	markQuery := `UPDATE outbox_events SET dispatched_at = NOW() WHERE id = $1`
+
+	for _, e := range pending {
+		if _, err := tx.ExecContext(ctx, fanOutQuery, e.id, e.eventType); err != nil {
+			return 0, err
+		}
+		if _, err := tx.ExecContext(ctx, markQuery, e.id); err != nil {
+			return 0, err
+		}
+	}
+
+	return len(pending), tx.Commit()
+}
+
+func (s *Store) ClaimDueDeliveries(ctx context.Context, limit int, lease time.Duration) ([]WebhookDelivery, error) {
+	query := `
+		UPDATE webhook_deliveries d
+		SET next_attempt_at = NOW() + $2 * INTERVAL '1 second'
+		FROM webhooks w, outbox_events e
+		WHERE d.id IN (
+			SELECT id FROM webhook_deliveries
+			WHERE status = 'pending' AND next_attempt_at <= NOW()
+			  AND webhook_id IN (SELECT id FROM webhooks WHERE enabled)
+			ORDER BY next_attempt_at
+			LIMIT $1
+			FOR UPDATE SKIP LOCKED
+		)
+		  AND w.id = d.webhook_id
+		  AND w.enabled
+		  AND e.id = d.outbox_event_id
+		RETURNING d.id, d.webhook_id, w.url, w.secret, e.event_type, e.payload, d.status, d.attempts, d.created_at
+	`
+	rows, err := s.DB.QueryContext(ctx, query, limit, lease.Seconds())
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var result []WebhookDelivery
+	for rows.Next() {
+		var d WebhookDelivery
+		var payload []byte
+		if err := rows.Scan(&d.ID, &d.WebhookID, &d.URL, &d.Secret, &d.EventType, &payload, &d.Status, &d.Attempts, &d.CreatedAt); err != nil {
+			return nil, err
+		}
+		d.Payload = payload
+		result = append(result, d)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+func (s *Store) MarkDeliverySucceeded(ctx context.Context, deliveryID int64, statusCode int) error {
+	query := `
+		UPDATE webhook_deliveries
+		SET status = 'delivered', attempts = attempts + 1, last_status_code = $1, last_error = NULL, finished_at = NOW()
+		WHERE id = $2
+	`
+	_, err := s.DB.ExecContext(ctx, query, statusCode, deliveryID)
+	return err
+}
+
+func (s *Store) MarkDeliveryFailed(ctx context.Context, deliveryID int64, statusCode *int, errMsg string, nextAttemptAt *time.Time) error {
+	if nextAttemptAt == nil {
+		query := `
+			UPDATE webhook_deliveries
+			SET status = 'dead', attempts = attempts + 1, last_status_code = $1, last_error = $2, finished_at = NOW()
+			WHERE id = $3
+		`
+		_, err := s.DB.ExecContext(ctx, query, statusCode, errMsg, deliveryID)
+		return err
+	}
+
+	query := `
+		UPDATE webhook_deliveries
+		SET attempts = attempts + 1, last_status_code = $1, last_error = $2, next_attempt_at = $3
+		WHERE id = $4
+	`
+	_, err := s.DB.ExecContext(ctx, query, statusCode, errMsg, *nextAttemptAt, deliveryID)
+	return err
+}
+
+// PurgeWebhookHistory удаляет доставки, завершенные (успешно или в dead letter)
+// раньше retention, и события outbox, разосланные раньше retention, у которых
+// не осталось доставок.
+func (s *Store) PurgeWebhookHistory(ctx context.Context, retention time.Duration) (int64, int64, error) {
+	tx, err := s.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, 0, err
+	}
+	defer tx.Rollback()
+
+	deleteDeliveriesQuery := `
+		DELETE FROM webhook_deliveries
+		WHERE status IN ('delivered', 'dead')
+		  AND finished_at < NOW() - $1 * INTERVAL '1 second'
+	`
+	deleteEventsQuery := `
+		DELETE FROM outbox_events e
+		WHERE e.dispatched_at < NOW() - $1 * INTERVAL '1 second'
+		  AND NOT EXISTS (SELECT 1 FROM webhook_deliveries d WHERE d.outbox_event_id = e.id)
+	`
+
+	res, err := tx.ExecContext(ctx, deleteDeliveriesQuery, retention.Seconds())
+	if err != nil {
+		return 0, 0, err
+	}
+	deliveries, _ := res.RowsAffected()
+
+	res, err = tx.ExecContext(ctx, deleteEventsQuery, retention.Seconds())
+	if err != nil {
+		return 0, 0, err
+	}
+	events, _ := res.RowsAffected()
+
+	return deliveries, events, tx.Commit()
+}
+
+func (s *Store) GetDeadLetters(ctx context.Context, limit int, offset int) ([]WebhookDelivery, error) {
+	query := `
+		SELECT id, webhook_id, url, event_type, payload, attempts, last_status_code, last_error, created_at, finished_at
+		FROM webhook_dead_letters
+		ORDER BY id DESC
+		LIMIT $1 OFFSET $2
+	`
+	rows, err := s.DB.QueryContext(ctx, query, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var result []WebhookDelivery
+	for rows.Next() {
+		d := WebhookDelivery{Status: "dead"}
+		var payload []byte
+		if err := rows.Scan(&d.ID, &d.WebhookID, &d.URL, &d.EventType, &payload, &d.Attempts, &d.LastStatus, &d.LastError, &d.CreatedAt, &d.FinishedAt); err != nil {
+			return nil, err
+		}
+		d.Payload = payload
+		result = append(result, d)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
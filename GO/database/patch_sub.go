@@ -0,0 +1,113 @@
+package database
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func (s *Store) PatchSubscription(ctx context.Context, userID uuid.UUID, serviceName string, patch SubPatch, expectedVersion *int64) (PatchResult, error) {
+	var result PatchResult
+
+	tx, err := s.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return result, err
+	}
+	defer tx.Rollback()
+
+	current, err := lockActiveSubscription(ctx, tx, userID, serviceName)
+	if err != nil {
+		return result, err
+	}
+
+	if expectedVersion != nil && *expectedVersion != current.Version {
+		return result, ErrSubVersionMismatch
+	}
+
+	before, err := loadSubSnapshot(ctx, tx, current.ID)
+	if err != nil {
+		return result, err
+	}
+
+	effectiveEndDate := current.EndDate
+	if patch.EndDateSet {
+		effectiveEndDate = patch.EndDate
+	}
+
+	if patch.StartDate != nil && !patch.StartDate.Equal(current.StartDate) {
+		if !current.StartDate.After(time.Now()) {
+			return result, ErrSubAlreadyStarted
+		}
+
+		if effectiveEndDate != nil && effectiveEndDate.Before(*patch.StartDate) {
+			return result, ErrSubInvalidPeriod
+		}
+
+		var conflictCount int
+		conflictQuery := `
+			SELECT COUNT(1)
+			FROM subscriptions
+			WHERE user_id = $1 AND service_name = $2 AND id <> $3
+			  AND deleted_at IS NULL
+			  AND (end_date IS NULL OR $4 <= end_date)
+			  AND ($5::date IS NULL OR $5 >= start_date)
+		`
+		err = tx.QueryRowContext(ctx, conflictQuery, current.UserID, current.ServiceName, current.ID, patch.StartDate, effectiveEndDate).Scan(&conflictCount)
+		if err != nil {
+			return result, err
+		}
+		if conflictCount > 0 {
+			return result, ErrSubOverlapExist
+		}
+
+		updateSub := `UPDATE subscriptions SET start_date=$1 WHERE id=$2`
+		if _, err := tx.ExecContext(ctx, updateSub, patch.StartDate, current.ID); err != nil {
+			return result, err
+		}
+
+		updateFirstPrice := `
+			UPDATE subscription_prices
+			SET valid_from = $1
+			WHERE id = (
+				SELECT id FROM subscription_prices
+				WHERE subscription_id = $2
+				ORDER BY valid_from ASC
+				LIMIT 1
+			)
+		`
+		if _, err := tx.ExecContext(ctx, updateFirstPrice, patch.StartDate, current.ID); err != nil {
+			return result, err
+		}
+
+		current.StartDate = *patch.StartDate
+		result.StartDateChanged = true
+	}
+
+	if effectiveEndDate != nil && effectiveEndDate.Before(current.StartDate) {
+		return result, ErrSubInvalidPeriod
+	}
+
+	if patch.Price != nil || patch.EndDateSet {
+		result.PriceChanged, result.EndDateChanged, result.OpType, err = applySubscriptionUpdate(ctx, tx, current, patch.Price, patch.EndDate, patch.EndDateSet)
+		if err != nil {
+			return PatchResult{}, err
+		}
+	}
+
+	if result.StartDateChanged || result.PriceChanged || result.EndDateChanged || result.OpType != "" {
+		if err := bumpSubscriptionVersion(ctx, tx, current.ID); err != nil {
+			return PatchResult{}, err
+		}
+
+		after, err := loadSubSnapshot(ctx, tx, current.ID)
+		if err != nil {
+			return PatchResult{}, err
+		}
+		if err := recordSubscriptionEvent(ctx, tx, updateEventType(result.OpType, result.StartDateChanged), before, after); err != nil {
+			return PatchResult{}, err
+		}
+	}
+
+	return result, tx.Commit()
+}
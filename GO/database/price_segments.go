@@ -0,0 +1,90 @@
+package database
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type PriceSegment struct {
+	SubscriptionID int
+	ServiceName    string
+	Price          int
+	From           time.Time
+	To             *time.Time
+	SubEndDate     *time.Time
+}
+
+func (s *Store) getPriceSegments(ctx context.Context, userID uuid.UUID, serviceName string, from, to time.Time) ([]PriceSegment, error) {
+	query := `
+		SELECT
+			s.id,
+			s.service_name,
+			sp.price,
+			GREATEST(sp.valid_from, s.start_date),
+			LEAST(sp.valid_to, s.end_date),
+			s.end_date
+		FROM subscriptions s
+		JOIN subscription_prices sp
+			ON sp.subscription_id = s.id
+		WHERE s.user_id = $1
+		  AND s.deleted_at IS NULL
+		  AND ($2 = '' OR s.service_name = $2)
+		  AND s.start_date <= $4
+		  AND (s.end_date IS NULL OR s.end_date >= $3)
+		  AND sp.valid_from <= $4
+		  AND (sp.valid_to IS NULL OR sp.valid_to >= $3)
+		ORDER BY s.service_name, s.id, sp.valid_from
+	`
+	rows, err := s.DB.QueryContext(ctx, query, userID, serviceName, from, to)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var result []PriceSegment
+	for rows.Next() {
+		var seg PriceSegment
+		if err := rows.Scan(&seg.SubscriptionID, &seg.ServiceName, &seg.Price, &seg.From, &seg.To, &seg.SubEndDate); err != nil {
+			return nil, err
+		}
+		if seg.To != nil && seg.To.Before(seg.From) {
+			continue
+		}
+		result = append(result, seg)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// eachSegmentMonth вызывает fn для каждого месяца периода и каждой подписки,
+// действовавшей в этом месяце. Если у подписки в месяце несколько сегментов цены,
+// учитывается последний из них: так же считает CalculateTotalSubscriptionCost
+// после повышения уровня в середине месяца.
+func eachSegmentMonth(segments []PriceSegment, from time.Time, months int, fn func(i int, month time.Time, seg PriceSegment)) {
+	for i := 0; i < months; i++ {
+		monthStart := time.Date(from.Year(), from.Month()+time.Month(i), 1, 0, 0, 0, 0, time.UTC)
+		monthEnd := endOfMonth(monthStart)
+
+		latest := make(map[int]PriceSegment)
+		var order []int
+		for _, seg := range segments {
+			if seg.From.After(monthEnd) || (seg.To != nil && seg.To.Before(monthStart)) {
+				continue
+			}
+			if _, ok := latest[seg.SubscriptionID]; !ok {
+				order = append(order, seg.SubscriptionID)
+			}
+			latest[seg.SubscriptionID] = seg
+		}
+
+		for _, subID := range order {
+			fn(i, monthStart, latest[subID])
+		}
+	}
+}
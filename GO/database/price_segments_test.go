@@ -0,0 +1,99 @@
+package database
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func date(year int, month time.Month, day int) time.Time {
+	return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+}
+
+func TestEachSegmentMonth(t *testing.T) {
+	type visit struct {
+		I     int
+		Month time.Time
+		SubID int
+		Price int
+	}
+
+	upgradeEnd := date(2025, 2, 14)
+	cancelEnd := date(2025, 2, 28)
+
+	tests := []struct {
+		name     string
+		segments []PriceSegment
+		from     time.Time
+		months   int
+		want     []visit
+	}{
+		{
+			name:   "без сегментов",
+			from:   date(2025, 1, 1),
+			months: 3,
+		},
+		{
+			name:     "бессрочная подписка",
+			segments: []PriceSegment{{SubscriptionID: 1, Price: 100, From: date(2025, 2, 1)}},
+			from:     date(2025, 1, 1),
+			months:   3,
+			want: []visit{
+				{I: 1, Month: date(2025, 2, 1), SubID: 1, Price: 100},
+				{I: 2, Month: date(2025, 3, 1), SubID: 1, Price: 100},
+			},
+		},
+		{
+			name: "повышение в середине месяца учитывается последним сегментом",
+			segments: []PriceSegment{
+				{SubscriptionID: 1, Price: 100, From: date(2025, 1, 1), To: &upgradeEnd},
+				{SubscriptionID: 1, Price: 200, From: date(2025, 2, 15)},
+			},
+			from:   date(2025, 1, 1),
+			months: 3,
+			want: []visit{
+				{I: 0, Month: date(2025, 1, 1), SubID: 1, Price: 100},
+				{I: 1, Month: date(2025, 2, 1), SubID: 1, Price: 200},
+				{I: 2, Month: date(2025, 3, 1), SubID: 1, Price: 200},
+			},
+		},
+		{
+			name: "несколько подписок и окончание подписки",
+			segments: []PriceSegment{
+				{SubscriptionID: 2, Price: 300, From: date(2025, 1, 10), To: &cancelEnd},
+				{SubscriptionID: 1, Price: 100, From: date(2024, 6, 1)},
+			},
+			from:   date(2025, 1, 1),
+			months: 3,
+			want: []visit{
+				{I: 0, Month: date(2025, 1, 1), SubID: 2, Price: 300},
+				{I: 0, Month: date(2025, 1, 1), SubID: 1, Price: 100},
+				{I: 1, Month: date(2025, 2, 1), SubID: 2, Price: 300},
+				{I: 1, Month: date(2025, 2, 1), SubID: 1, Price: 100},
+				{I: 2, Month: date(2025, 3, 1), SubID: 1, Price: 100},
+			},
+		},
+		{
+			name:     "переход через год",
+			segments: []PriceSegment{{SubscriptionID: 1, Price: 50, From: date(2025, 12, 31)}},
+			from:     date(2025, 11, 20),
+			months:   3,
+			want: []visit{
+				{I: 1, Month: date(2025, 12, 1), SubID: 1, Price: 50},
+				{I: 2, Month: date(2026, 1, 1), SubID: 1, Price: 50},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []visit
+			eachSegmentMonth(tt.segments, tt.from, tt.months, func(i int, month time.Time, seg PriceSegment) {
+				got = append(got, visit{I: i, Month: month, SubID: seg.SubscriptionID, Price: seg.Price})
+			})
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("eachSegmentMonth:\nполучено  %+v\nожидалось %+v", got, tt.want)
+			}
+		})
+	}
+}
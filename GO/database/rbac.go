@@ -0,0 +1,27 @@
+package database
+
+import "context"
+
+func (s *Store) GetRolePermissions(ctx context.Context) (map[string][]string, error) {
+	query := `SELECT role, permission FROM role_permissions ORDER BY role, permission`
+	rows, err := s.DB.QueryContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	result := make(map[string][]string)
+	for rows.Next() {
+		var role, permission string
+		if err := rows.Scan(&role, &permission); err != nil {
+			return nil, err
+		}
+		result[role] = append(result[role], permission)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
@@ -0,0 +1,32 @@
+package database
+
+const (
+	PermSubscriptionsRead    = "subscriptions:read"
+	PermSubscriptionsWrite   = "subscriptions:write"
+	PermSubscriptionsRestore = "subscriptions:restore"
+	PermReportsRead          = "reports:read"
+	PermAnalyticsRead        = "analytics:read"
+	PermWebhooksManage       = "webhooks:manage"
+	PermAPIKeysManage        = "api_keys:manage"
+	PermUsersAny             = "users:any"
+)
+
+var Permissions = []string{
+	PermSubscriptionsRead,
+	PermSubscriptionsWrite,
+	PermSubscriptionsRestore,
+	PermReportsRead,
+	PermAnalyticsRead,
+	PermWebhooksManage,
+	PermAPIKeysManage,
+	PermUsersAny,
+}
+
+const (
+	RoleUser    = "user"
+	RoleSupport = "support"
+	RoleFinance = "finance"
+	RoleAdmin   = "admin"
+)
+
+var Roles = []string{RoleUser, RoleSupport, RoleFinance, RoleAdmin}
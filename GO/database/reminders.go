@@ -0,0 +1,95 @@
+package database
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type Reminder struct {
+	Kind           string
+	SubscriptionID int
+	UserID         uuid.UUID
+	ServiceName    string
+	Price          int
+	NewPrice       *int
+	TargetDate     time.Time
+	Email          *string
+}
+
+// GetUpcomingReminders возвращает неотправленные напоминания. При requireEmail
+// пропускаются пользователи без адреса: они попадут в выборку, когда адрес появится.
+func (s *Store) GetUpcomingReminders(ctx context.Context, daysAhead int, requireEmail bool) ([]Reminder, error) {
+	query := `
+		SELECT 'expiry', s.id, s.user_id, s.service_name, s.price, NULL::int, s.end_date, c.email
+		FROM subscriptions s
+		LEFT JOIN user_contacts c ON c.user_id = s.user_id
+		WHERE s.deleted_at IS NULL
+		  AND s.end_date IS NOT NULL
+		  AND s.end_date >= CURRENT_DATE
+		  AND s.end_date <= CURRENT_DATE + $1::int
+		  AND (NOT $2 OR c.email IS NOT NULL)
+		  AND NOT EXISTS (
+			SELECT 1 FROM sent_reminders r
+			WHERE r.subscription_id = s.id AND r.kind = 'expiry' AND r.target_date = s.end_date
+		  )
+
+		UNION ALL
+
+		SELECT 'downgrade', s.id, s.user_id, s.service_name, sp.previous_price, sp.price, sp.valid_from, c.email
+		FROM subscription_prices sp
+		JOIN subscriptions s ON s.id = sp.subscription_id
+		LEFT JOIN user_contacts c ON c.user_id = s.user_id
+		WHERE s.deleted_at IS NULL
+		  AND sp.previous_price IS NOT NULL
+		  AND sp.price < sp.previous_price
+		  AND sp.valid_from > CURRENT_DATE
+		  AND sp.valid_from <= CURRENT_DATE + $1::int
+		  AND (NOT $2 OR c.email IS NOT NULL)
+		  AND NOT EXISTS (
+			SELECT 1 FROM sent_reminders r
+			WHERE r.subscription_id = s.id AND r.kind = 'downgrade' AND r.target_date = sp.valid_from
+		  )
+	`
+	rows, err := s.DB.QueryContext(ctx, query, daysAhead, requireEmail)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var result []Reminder
+	for rows.Next() {
+		var r Reminder
+		if err := rows.Scan(&r.Kind, &r.SubscriptionID, &r.UserID, &r.ServiceName, &r.Price, &r.NewPrice, &r.TargetDate, &r.Email); err != nil {
+			return nil, err
+		}
+		result = append(result, r)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+func (s *Store) MarkReminderSent(ctx context.Context, subscriptionID int, kind string, targetDate time.Time) error {
+	query := `
+		INSERT INTO sent_reminders (subscription_id, kind, target_date)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (subscription_id, kind, target_date) DO NOTHING
+	`
+	_, err := s.DB.ExecContext(ctx, query, subscriptionID, kind, targetDate)
+	return err
+}
+
+func (s *Store) SetUserContact(ctx context.Context, userID uuid.UUID, email string) error {
+	query := `
+		INSERT INTO user_contacts (user_id, email)
+		VALUES ($1, $2)
+		ON CONFLICT (user_id) DO UPDATE SET email = EXCLUDED.email, updated_at = NOW()
+	`
+	_, err := s.DB.ExecContext(ctx, query, userID, email)
+	return err
+}
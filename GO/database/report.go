@@ -0,0 +1,38 @@
+package database
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type ReportMonth struct {
+	Month    time.Time
+	Services map[string]int
+	Total    int
+}
+
+func (s *Store) GetMonthlySpendReport(ctx context.Context, userID uuid.UUID, from, to time.Time) ([]ReportMonth, error) {
+	from = time.Date(from.Year(), from.Month(), 1, 0, 0, 0, 0, time.UTC)
+	to = endOfMonth(to)
+	months := (to.Year()-from.Year())*12 + int(to.Month()-from.Month()) + 1
+
+	segments, err := s.getPriceSegments(ctx, userID, "", from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]ReportMonth, months)
+	for i := range result {
+		result[i].Month = from.AddDate(0, i, 0)
+		result[i].Services = make(map[string]int)
+	}
+
+	eachSegmentMonth(segments, from, months, func(i int, _ time.Time, seg PriceSegment) {
+		result[i].Services[seg.ServiceName] += seg.Price
+		result[i].Total += seg.Price
+	})
+
+	return result, nil
+}
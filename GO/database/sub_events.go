@@ -0,0 +1,251 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type AuditInfo struct {
+	Actor     string
+	RequestID string
+}
+
+type auditInfoKey struct{}
+
+func WithAuditInfo(ctx context.Context, info AuditInfo) context.Context {
+	return context.WithValue(ctx, auditInfoKey{}, info)
+}
+
+func auditInfoFromContext(ctx context.Context) AuditInfo {
+	info, _ := ctx.Value(auditInfoKey{}).(AuditInfo)
+	if info.Actor == "" {
+		info.Actor = "anonymous"
+	}
+	return info
+}
+
+type priceSnapshot struct {
+	Price         int        `json:"price"`
+	PreviousPrice *int       `json:"previous_price,omitempty"`
+	ValidFrom     time.Time  `json:"valid_from"`
+	ValidTo       *time.Time `json:"valid_to"`
+}
+
+type subSnapshot struct {
+	ID          int             `json:"id"`
+	UserID      uuid.UUID       `json:"user_id"`
+	ServiceName string          `json:"service_name"`
+	Price       int             `json:"price"`
+	StartDate   time.Time       `json:"start_date"`
+	EndDate     *time.Time      `json:"end_date"`
+	Version     int64           `json:"version"`
+	DeletedAt   *time.Time      `json:"deleted_at,omitempty"`
+	Prices      []priceSnapshot `json:"prices"`
+}
+
+func loadSubSnapshot(ctx context.Context, tx *sql.Tx, subID int) (*subSnapshot, error) {
+	var snap subSnapshot
+	subQuery := `
+		SELECT id, user_id, service_name, price, start_date, end_date, version, deleted_at
+		FROM subscriptions
+		WHERE id = $1
+	`
+	if err := tx.QueryRowContext(ctx, subQuery, subID).Scan(
+		&snap.ID, &snap.UserID, &snap.ServiceName, &snap.Price, &snap.StartDate, &snap.EndDate, &snap.Version, &snap.DeletedAt,
+	); err != nil {
+		return nil, err
+	}
+
+	pricesQuery := `
+		SELECT price, previous_price, valid_from, valid_to
+		FROM subscription_prices
+		WHERE subscription_id = $1
+		ORDER BY valid_from ASC
+	`
+	rows, err := tx.QueryContext(ctx, pricesQuery, subID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var p priceSnapshot
+		if err := rows.Scan(&p.Price, &p.PreviousPrice, &p.ValidFrom, &p.ValidTo); err != nil {
+			return nil, err
+		}
+		snap.Prices = append(snap.Prices, p)
+	}
+
+	return &snap, rows.Err()
+}
+
+func recordSubscriptionEvent(ctx context.Context, tx *sql.Tx, eventType string, before, after *subSnapshot) error {
+	ref := after
+	if ref == nil {
+		ref = before
+	}
+
+	var beforeJSON, afterJSON []byte
+	var err error
+	if before != nil {
+		if beforeJSON, err = json.Marshal(before); err != nil {
+			return err
+		}
+	}
+	if after != nil {
+		if afterJSON, err = json.Marshal(after); err != nil {
+			return err
+		}
+	}
+
+	info := auditInfoFromContext(ctx)
+	var requestID *string
+	if info.RequestID != "" {
+		requestID = &info.RequestID
+	}
+
+	query := `
+		INSERT INTO subscription_events (subscription_id, user_id, service_name, event_type, actor, request_id, before_state, after_state)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+	`
+	if _, err = tx.ExecContext(ctx, query, ref.ID, ref.UserID, ref.ServiceName, eventType, info.Actor, requestID, nullableJSON(beforeJSON), nullableJSON(afterJSON)); err != nil {
+		return err
+	}
+
+	if err = invalidateAnalyticsCache(ctx, tx, before, after); err != nil {
+		return err
+	}
+
+	outboxType, ok := outboxEventTypes[eventType]
+	if !ok {
+		return nil
+	}
+
+	_, err = enqueueOutboxEvent(ctx, tx, outboxType, map[string]any{
+		"user_id":      ref.UserID,
+		"service_name": ref.ServiceName,
+		"actor":        info.Actor,
+		"before":       before,
+		"after":        after,
+	})
+	return err
+}
+
+func nullableJSON(b []byte) any {
+	if b == nil {
+		return nil
+	}
+	return string(b)
+}
+
+func updateEventType(opType string, startDateChanged bool) string {
+	switch {
+	case opType != "":
+		return opType
+	case startDateChanged:
+		return "start_date_change"
+	default:
+		return "date_change"
+	}
+}
+
+func (s *Store) GetSubscriptionEvents(ctx context.Context, userID uuid.UUID, serviceName string, limit int, offset int) ([]SubEvent, error) {
+	query := `
+		SELECT id, subscription_id, user_id, service_name, event_type, actor, request_id, before_state, after_state, created_at
+		FROM subscription_events
+		WHERE user_id = $1 AND service_name = $2
+		ORDER BY id DESC
+		LIMIT $3 OFFSET $4
+	`
+	rows, err := s.DB.QueryContext(ctx, query, userID, serviceName, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var result []SubEvent
+	for rows.Next() {
+		var e SubEvent
+		var before, after []byte
+		if err := rows.Scan(
+			&e.ID, &e.SubscriptionID, &e.UserID, &e.ServiceName, &e.EventType, &e.Actor, &e.RequestID, &before, &after, &e.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		e.Before = before
+		e.After = after
+		result = append(result, e)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// GetSubscriptionEventCursor возвращает текущий снимок транзакций: поток,
+// начатый с него, получит только события транзакций, зафиксированных позже.
+func (s *Store) GetSubscriptionEventCursor(ctx context.Context) (EventCursor, error) {
+	var raw string
+	if err := s.DB.QueryRowContext(ctx, `SELECT pg_current_snapshot()::text`).Scan(&raw); err != nil {
+		return EventCursor{}, err
+	}
+	return ParseEventCursor(raw)
+}
+
+// GetSubscriptionEventsBetween возвращает события транзакций, которые завершены
+// в снимке to, но не были завершены в снимке from, в порядке (tx_id, id) после
+// позиции after. Транзакция, выполняющаяся дольше остальных, задерживает только
+// свои события: ее записи попадут в окно, снимок которого застанет ее фиксацию.
+func (s *Store) GetSubscriptionEventsBetween(ctx context.Context, from, to EventCursor, after EventPosition, userID *uuid.UUID, limit int) ([]SubEvent, error) {
+	query := `
+		SELECT id, subscription_id, user_id, service_name, event_type, actor, request_id, before_state, after_state, created_at, tx_id::text::bigint
+		FROM subscription_events
+		WHERE tx_id >= pg_snapshot_xmin($1::pg_snapshot)
+		  AND tx_id < pg_snapshot_xmax($2::pg_snapshot)
+		  AND NOT pg_visible_in_snapshot(tx_id, $1::pg_snapshot)
+		  AND pg_visible_in_snapshot(tx_id, $2::pg_snapshot)
+		  AND (tx_id, id) > ($3::bigint::text::xid8, $4)
+	`
+	args := []any{from.String(), to.String(), int64(after.TxID), after.ID}
+
+	if userID != nil {
+		query += fmt.Sprintf(" AND user_id = $%d", len(args)+1)
+		args = append(args, *userID)
+	}
+
+	query += fmt.Sprintf(" ORDER BY tx_id ASC, id ASC LIMIT $%d", len(args)+1)
+	args = append(args, limit)
+
+	rows, err := s.DB.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var result []SubEvent
+	for rows.Next() {
+		var e SubEvent
+		var before, after []byte
+		if err := rows.Scan(
+			&e.ID, &e.SubscriptionID, &e.UserID, &e.ServiceName, &e.EventType, &e.Actor, &e.RequestID, &before, &after, &e.CreatedAt, &e.TxID,
+		); err != nil {
+			return nil, err
+		}
+		e.Before = before
+		e.After = after
+		result = append(result, e)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
@@ -1,7 +1,11 @@
 package database
 
 import (
+	"encoding/json"
 	"errors"
+	"fmt"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
@@ -14,6 +18,7 @@ type Subs struct {
 	Price       int        `json:"price"`
 	StartDate   time.Time  `json:"start_date"`
 	EndDate     *time.Time `json:"end_date"`
+	Version     int64      `json:"-"`
 }
 
 type SubsPriceHistory struct {
@@ -24,6 +29,90 @@ type SubsPriceHistory struct {
 	ValidTo        *time.Time `json:"valid_to"`
 }
 
+type SubEvent struct {
+	ID             int64           `json:"id"`
+	SubscriptionID int             `json:"subscription_id"`
+	UserID         uuid.UUID       `json:"user_id"`
+	ServiceName    string          `json:"service_name"`
+	EventType      string          `json:"event_type"`
+	Actor          string          `json:"actor"`
+	RequestID      *string         `json:"request_id,omitempty"`
+	Before         json.RawMessage `json:"before,omitempty"`
+	After          json.RawMessage `json:"after,omitempty"`
+	CreatedAt      time.Time       `json:"created_at"`
+	TxID           uint64          `json:"-"`
+}
+
+// EventCursor — позиция в потоке событий в виде снимка транзакций PostgreSQL
+// (pg_snapshot, формат xmin:xmax:xip1,xip2). События транзакций, завершенных
+// к моменту снимка, уже отданы; остальные будут отданы после фиксации.
+type EventCursor struct {
+	Xmin uint64
+	Xmax uint64
+	Xip  []uint64
+}
+
+func (c EventCursor) String() string {
+	xip := make([]string, len(c.Xip))
+	for i, x := range c.Xip {
+		xip[i] = strconv.FormatUint(x, 10)
+	}
+	return fmt.Sprintf("%d:%d:%s", c.Xmin, c.Xmax, strings.Join(xip, ","))
+}
+
+func ParseEventCursor(raw string) (EventCursor, error) {
+	invalid := fmt.Errorf("некорректная позиция в потоке событий: %q", raw)
+
+	parts := strings.Split(raw, ":")
+	if len(parts) != 3 {
+		return EventCursor{}, invalid
+	}
+	xmin, err := strconv.ParseUint(parts[0], 10, 64)
+	if err != nil || xmin == 0 {
+		return EventCursor{}, invalid
+	}
+	xmax, err := strconv.ParseUint(parts[1], 10, 64)
+	if err != nil || xmax < xmin {
+		return EventCursor{}, invalid
+	}
+
+	cursor := EventCursor{Xmin: xmin, Xmax: xmax}
+	if parts[2] == "" {
+		return cursor, nil
+	}
+	for _, part := range strings.Split(parts[2], ",") {
+		x, err := strconv.ParseUint(part, 10, 64)
+		if err != nil || x < xmin || x >= xmax || (len(cursor.Xip) > 0 && x <= cursor.Xip[len(cursor.Xip)-1]) {
+			return EventCursor{}, invalid
+		}
+		cursor.Xip = append(cursor.Xip, x)
+	}
+	return cursor, nil
+}
+
+// EventPosition — последнее отданное событие внутри окна между двумя снимками.
+type EventPosition struct {
+	TxID uint64
+	ID   int64
+}
+
+type SubPatch struct {
+	Price      *int
+	StartDate  *time.Time
+	EndDate    *time.Time
+	EndDateSet bool
+}
+
+type PatchResult struct {
+	StartDateChanged bool
+	PriceChanged     bool
+	EndDateChanged   bool
+	OpType           string
+}
+
 var ErrSubIsExist = errors.New("подписка существует")
 var ErrSubOverlapExist = errors.New("подписка пересекается с другой")
 var ErrSubNotFound = errors.New("подписка не найдена")
+var ErrSubVersionMismatch = errors.New("версия подписки не совпадает с ожидаемой")
+var ErrSubAlreadyStarted = errors.New("подписка уже началась")
+var ErrSubInvalidPeriod = errors.New("дата окончания подписки раньше даты начала")
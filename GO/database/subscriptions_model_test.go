@@ -0,0 +1,52 @@
+package database
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseEventCursor(t *testing.T) {
+	tests := []struct {
+		raw     string
+		want    EventCursor
+		wantErr bool
+	}{
+		{raw: "100:100:", want: EventCursor{Xmin: 100, Xmax: 100}},
+		{raw: "100:105:", want: EventCursor{Xmin: 100, Xmax: 105}},
+		{raw: "100:105:100,103", want: EventCursor{Xmin: 100, Xmax: 105, Xip: []uint64{100, 103}}},
+		{raw: "", wantErr: true},
+		{raw: "100", wantErr: true},
+		{raw: "100:105", wantErr: true},
+		{raw: "100:105:1:2", wantErr: true},
+		{raw: "0:5:", wantErr: true},
+		{raw: "105:100:", wantErr: true},
+		{raw: "a:105:", wantErr: true},
+		{raw: "100:105:99", wantErr: true},
+		{raw: "100:105:105", wantErr: true},
+		{raw: "100:105:103,101", wantErr: true},
+		{raw: "100:105:101,101", wantErr: true},
+		{raw: "100:105:101,", wantErr: true},
+		{raw: "-1:105:", wantErr: true},
+		{raw: "42-7", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		got, err := ParseEventCursor(tt.raw)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("ParseEventCursor(%q): ожидалась ошибка, получено %+v", tt.raw, got)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("ParseEventCursor(%q): неожиданная ошибка %v", tt.raw, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("ParseEventCursor(%q) = %+v, ожидалось %+v", tt.raw, got, tt.want)
+		}
+		if got.String() != tt.raw {
+			t.Errorf("String() = %q, ожидалось %q", got.String(), tt.raw)
+		}
+	}
+}
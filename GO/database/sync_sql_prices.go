@@ -2,16 +2,75 @@ package database
 
 import "context"
 
+// SyncSubscriptionPrices переносит в subscriptions цену, действующую на текущую
+// дату. Каждое изменение записывается в журнал событий от имени system:price_sync.
 func (s *Store) SyncSubscriptionPrices(ctx context.Context) error {
+	ctx = WithAuditInfo(ctx, AuditInfo{Actor: "system:price_sync"})
+
+	tx, err := s.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	staleQuery := `
+	SELECT s.id
+	FROM subscriptions s
+	JOIN subscription_prices sp ON sp.subscription_id = s.id
+	WHERE s.deleted_at IS NULL
+	  AND sp.valid_from <= CURRENT_DATE
+	  AND (sp.valid_to IS NULL OR sp.valid_to >= CURRENT_DATE)
+	  AND s.price <> sp.price
+	ORDER BY s.id
+	FOR UPDATE OF s
+	`
+	rows, err := tx.QueryContext(ctx, staleQuery)
+	if err != nil {
+		return err
+	}
+	var ids []int
+	for rows.Next() {
+		var id int
+		if err := rows.Scan(&id); err != nil {
+			rows.Close()
+			return err
+		}
+		ids = append(ids, id)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
 	query := `
 	UPDATE subscriptions s
 	SET price = sp.price
 	FROM subscription_prices sp
-	WHERE s.id = sp.subscription_id
+	WHERE s.id = $1
+	  AND s.id = sp.subscription_id
 	  AND sp.valid_from <= CURRENT_DATE
-	  AND sp.valid_to >= CURRENT_DATE
+	  AND (sp.valid_to IS NULL OR sp.valid_to >= CURRENT_DATE)
 	  AND s.price <> sp.price
 	`
-	_, err := s.DB.ExecContext(ctx, query)
-	return err
+	for _, id := range ids {
+		before, err := loadSubSnapshot(ctx, tx, id)
+		if err != nil {
+			return err
+		}
+		if _, err := tx.ExecContext(ctx, query, id); err != nil {
+			return err
+		}
+		if err := bumpSubscriptionVersion(ctx, tx, id); err != nil {
+			return err
+		}
+		after, err := loadSubSnapshot(ctx, tx, id)
+		if err != nil {
+			return err
+		}
+		if err := recordSubscriptionEvent(ctx, tx, "price_sync", before, after); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
 }
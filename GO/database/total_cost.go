@@ -12,7 +12,7 @@ func (s *Store) CalculateTotalSubscriptionCost(ctx context.Context, userID uuid.
 	checkQuery := `
 		SELECT EXISTS (
 			SELECT 1 FROM subscriptions
-			WHERE user_id = $1 AND service_name = $2)
+			WHERE user_id = $1 AND service_name = $2 AND deleted_at IS NULL)
 	`
 
 	if err := s.DB.QueryRowContext(ctx, checkQuery, userID, serviceName).Scan(&exists); err != nil {
@@ -36,10 +36,11 @@ func (s *Store) CalculateTotalSubscriptionCost(ctx context.Context, userID uuid.
 			ON sp.subscription_id = s.id
 		WHERE s.user_id = $1
 		  AND s.service_name = $2
+		  AND s.deleted_at IS NULL
 		  AND s.start_date <= $4
-		  AND s.end_date   >= $3
+		  AND (s.end_date IS NULL OR s.end_date >= $3)
 		  AND sp.valid_from <= $4
-		  AND sp.valid_to   >= $3
+		  AND (sp.valid_to IS NULL OR sp.valid_to >= $3)
 		ORDER BY overlap_start;
 	`
 
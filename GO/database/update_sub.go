@@ -10,32 +10,88 @@ import (
 	"github.com/google/uuid"
 )
 
-func (s *Store) UpdateSubscription(ctx context.Context, userID uuid.UUID, serviceName string, newPrice *int, newEndDate *time.Time, newEndDateProvided bool) (priceChanged bool, endDateChanged bool, opType string, err error) {
+func (s *Store) UpdateSubscription(ctx context.Context, userID uuid.UUID, serviceName string, newPrice *int, newEndDate *time.Time, newEndDateProvided bool, expectedVersion *int64) (priceChanged bool, endDateChanged bool, opType string, err error) {
 	tx, err := s.DB.BeginTx(ctx, nil)
 	if err != nil {
 		return false, false, "", err
 	}
 	defer tx.Rollback()
 
+	current, err := lockActiveSubscription(ctx, tx, userID, serviceName)
+	if err != nil {
+		return false, false, "", err
+	}
+
+	if expectedVersion != nil && *expectedVersion != current.Version {
+		return false, false, "", ErrSubVersionMismatch
+	}
+
+	before, err := loadSubSnapshot(ctx, tx, current.ID)
+	if err != nil {
+		return false, false, "", err
+	}
+
+	priceChanged, endDateChanged, opType, err = applySubscriptionUpdate(ctx, tx, current, newPrice, newEndDate, newEndDateProvided)
+	if err != nil {
+		return false, false, "", err
+	}
+
+	if opType != "" || priceChanged || endDateChanged {
+		if err := bumpSubscriptionVersion(ctx, tx, current.ID); err != nil {
+			return false, false, "", err
+		}
+
+		after, err := loadSubSnapshot(ctx, tx, current.ID)
+		if err != nil {
+			return false, false, "", err
+		}
+		if err := recordSubscriptionEvent(ctx, tx, updateEventType(opType, false), before, after); err != nil {
+			return false, false, "", err
+		}
+	}
+
+	return priceChanged, endDateChanged, opType, tx.Commit()
+}
+
+func lockActiveSubscription(ctx context.Context, tx *sql.Tx, userID uuid.UUID, serviceName string) (*Subs, error) {
 	var current Subs
 	query := `
-		SELECT id, user_id, service_name, price, start_date, end_date
+		SELECT id, user_id, service_name, price, start_date, end_date, version
 		FROM subscriptions
 		WHERE user_id = $1
 		  AND service_name = $2
-		  AND end_date >= CURRENT_DATE
+		  AND deleted_at IS NULL
+		  AND (end_date IS NULL OR end_date >= CURRENT_DATE)
+		FOR UPDATE
 	`
-	err = tx.QueryRowContext(ctx, query, userID, serviceName).Scan(
+	err := tx.QueryRowContext(ctx, query, userID, serviceName).Scan(
 		&current.ID, &current.UserID, &current.ServiceName,
-		&current.Price, &current.StartDate, &current.EndDate,
+		&current.Price, &current.StartDate, &current.EndDate, &current.Version,
 	)
 	if errors.Is(err, sql.ErrNoRows) {
-		return false, false, "", ErrSubNotFound
+		return nil, ErrSubNotFound
 	}
 	if err != nil {
-		return false, false, "", err
+		return nil, err
+	}
+
+	return &current, nil
+}
+
+func bumpSubscriptionVersion(ctx context.Context, tx *sql.Tx, subID int) error {
+	query := `UPDATE subscriptions SET version = nextval('subscription_version_seq') WHERE id = $1`
+	_, err := tx.ExecContext(ctx, query, subID)
+	return err
+}
+
+func sameEndDate(a, b *time.Time) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
 	}
+	return a.Equal(*b)
+}
 
+func applySubscriptionUpdate(ctx context.Context, tx *sql.Tx, current *Subs, newPrice *int, newEndDate *time.Time, newEndDateProvided bool) (priceChanged bool, endDateChanged bool, opType string, err error) {
 	today := time.Now()
 	currentMonthStart := time.Date(today.Year(), today.Month(), 1, 0, 0, 0, 0, today.Location())
 	endOfPrevMonth := time.Date(today.Year(), today.Month(), 0, 23, 59, 59, 0, today.Location())
@@ -61,7 +117,7 @@ func (s *Store) UpdateSubscription(ctx context.Context, userID uuid.UUID, servic
 	endDateChanged = false
 
 	if newPrice == nil && newEndDateProvided {
-		if !current.EndDate.Equal(*newEndDate) {
+		if !sameEndDate(current.EndDate, newEndDate) {
 			updateSub := `UPDATE subscriptions SET end_date=$1 WHERE id=$2`
 			if _, err := tx.ExecContext(ctx, updateSub, newEndDate, current.ID); err != nil {
 				return false, false, "", err
@@ -82,10 +138,10 @@ func (s *Store) UpdateSubscription(ctx context.Context, userID uuid.UUID, servic
 				}
 			}
 
-			return false, true, "date_change", tx.Commit()
+			return false, true, "date_change", nil
 		}
 
-		return false, false, "", tx.Commit()
+		return false, false, "", nil
 	}
 
 	if newPrice != nil && *newPrice > current.Price {
@@ -118,7 +174,7 @@ func (s *Store) UpdateSubscription(ctx context.Context, userID uuid.UUID, servic
 			updateSubQuery := `UPDATE subscriptions SET price=$1`
 			args := []any{*newPrice}
 
-			if newEndDateProvided && !current.EndDate.Equal(*newEndDate) {
+			if newEndDateProvided && !sameEndDate(current.EndDate, newEndDate) {
 				updateSubQuery += ", end_date=$2"
 				args = append(args, newEndDate)
 				endDateChanged = true
@@ -132,7 +188,7 @@ func (s *Store) UpdateSubscription(ctx context.Context, userID uuid.UUID, servic
 			}
 
 			effectiveValidTo := current.EndDate
-			if newEndDateProvided && !current.EndDate.Equal(*newEndDate) {
+			if newEndDateProvided && !sameEndDate(current.EndDate, newEndDate) {
 				effectiveValidTo = newEndDate
 			}
 
@@ -153,7 +209,7 @@ func (s *Store) UpdateSubscription(ctx context.Context, userID uuid.UUID, servic
 				}
 			}
 
-			return priceChanged, endDateChanged, "upgrade", tx.Commit()
+			return priceChanged, endDateChanged, "upgrade", nil
 		}
 
 		updatePrevValidTo := `
@@ -167,7 +223,7 @@ func (s *Store) UpdateSubscription(ctx context.Context, userID uuid.UUID, servic
 
 		updateSubQuery := `UPDATE subscriptions SET price=$1`
 		args := []any{*newPrice}
-		if newEndDateProvided && !current.EndDate.Equal(*newEndDate) {
+		if newEndDateProvided && !sameEndDate(current.EndDate, newEndDate) {
 			updateSubQuery += ", end_date=$2"
 			args = append(args, newEndDate)
 			endDateChanged = true
@@ -180,7 +236,7 @@ func (s *Store) UpdateSubscription(ctx context.Context, userID uuid.UUID, servic
 		}
 
 		var validTo *time.Time
-		if newEndDateProvided && newEndDate != nil {
+		if newEndDateProvided {
 			validTo = newEndDate
 		} else {
 			validTo = current.EndDate
@@ -194,14 +250,14 @@ func (s *Store) UpdateSubscription(ctx context.Context, userID uuid.UUID, servic
 			return false, false, "", err
 		}
 
-		return priceChanged, endDateChanged, "upgrade", tx.Commit()
+		return priceChanged, endDateChanged, "upgrade", nil
 	}
 
 	if newPrice != nil && *newPrice < current.Price {
 		priceChanged = true
 		validFrom := firstNextMonth
 
-		if newEndDateProvided && !current.EndDate.Equal(*newEndDate) {
+		if newEndDateProvided && !sameEndDate(current.EndDate, newEndDate) {
 			updateSubQuery := `UPDATE subscriptions SET end_date=$1 WHERE id=$2`
 			if _, err := tx.ExecContext(ctx, updateSubQuery, newEndDate, current.ID); err != nil {
 				return false, false, "", err
@@ -238,7 +294,7 @@ func (s *Store) UpdateSubscription(ctx context.Context, userID uuid.UUID, servic
 			if _, err := tx.ExecContext(ctx, updateFuture, *newPrice, current.Price, effectiveEndDate, futureID); err != nil {
 				return false, false, "", err
 			}
-			return priceChanged, endDateChanged, "downgrade", tx.Commit()
+			return priceChanged, endDateChanged, "downgrade", nil
 		}
 
 		if lastPriceID != 0 {
@@ -256,11 +312,11 @@ func (s *Store) UpdateSubscription(ctx context.Context, userID uuid.UUID, servic
 			return false, false, "", err
 		}
 
-		return priceChanged, endDateChanged, "downgrade", tx.Commit()
+		return priceChanged, endDateChanged, "downgrade", nil
 	}
 
 	if newPrice != nil && *newPrice == current.Price {
-		if newEndDateProvided && !current.EndDate.Equal(*newEndDate) {
+		if newEndDateProvided && !sameEndDate(current.EndDate, newEndDate) {
 			updateSub := `UPDATE subscriptions SET end_date=$1 WHERE id=$2`
 			if _, err := tx.ExecContext(ctx, updateSub, newEndDate, current.ID); err != nil {
 				return false, false, "", err
@@ -295,7 +351,7 @@ func (s *Store) UpdateSubscription(ctx context.Context, userID uuid.UUID, servic
     `
 		err := tx.QueryRowContext(ctx, futureQuery, current.ID).Scan(&futureID, &futureStart, &futureValidTo)
 		if errors.Is(err, sql.ErrNoRows) {
-			return priceChanged, endDateChanged, "", tx.Commit()
+			return priceChanged, endDateChanged, "", nil
 		}
 		if err != nil {
 			return false, false, "", err
@@ -335,7 +391,7 @@ func (s *Store) UpdateSubscription(ctx context.Context, userID uuid.UUID, servic
 			return false, false, "", err
 		}
 
-		return priceChanged, endDateChanged, "rollback", tx.Commit()
+		return priceChanged, endDateChanged, "rollback", nil
 	}
 
 	return false, false, "", nil
@@ -0,0 +1,196 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/lib/pq"
+)
+
+func (s *Store) CreateWebhook(ctx context.Context, w *Webhook) error {
+	query := `
+		INSERT INTO webhooks (url, secret, event_types, enabled)
+		VALUES ($1, $2, $3, $4)
+		RETURNING id, created_at
+	`
+	return s.DB.QueryRowContext(ctx, query, w.URL, w.Secret, pq.Array(w.EventTypes), w.Enabled).Scan(&w.ID, &w.CreatedAt)
+}
+
+func (s *Store) GetWebhooks(ctx context.Context) ([]Webhook, error) {
+	query := `
+		SELECT id, url, secret, event_types, enabled, created_at
+		FROM webhooks
+		ORDER BY id
+	`
+	rows, err := s.DB.QueryContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var result []Webhook
+	for rows.Next() {
+		var w Webhook
+		if err := rows.Scan(&w.ID, &w.URL, &w.Secret, pq.Array(&w.EventTypes), &w.Enabled, &w.CreatedAt); err != nil {
+			return nil, err
+		}
+		result = append(result, w)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+func (s *Store) GetWebhook(ctx context.Context, id int) (*Webhook, error) {
+	var w Webhook
+	query := `
+		SELECT id, url, secret, event_types, enabled, created_at
+		FROM webhooks
+		WHERE id = $1
+	`
+	err := s.DB.QueryRowContext(ctx, query, id).Scan(&w.ID, &w.URL, &w.Secret, pq.Array(&w.EventTypes), &w.Enabled, &w.CreatedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrWebhookNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &w, nil
+}
+
+func (s *Store) UpdateWebhook(ctx context.Context, id int, patch WebhookPatch) (*Webhook, error) {
+	query := `UPDATE webhooks SET id = id`
+	var args []any
+
+	if patch.URL != nil {
+		args = append(args, *patch.URL)
+		query += fmt.Sprintf(", url = $%d", len(args))
+	}
+	if patch.Secret != nil {
+		args = append(args, *patch.Secret)
+		query += fmt.Sprintf(", secret = $%d", len(args))
+	}
+	if patch.EventTypes != nil {
+		args = append(args, pq.Array(*patch.EventTypes))
+		query += fmt.Sprintf(", event_types = $%d", len(args))
+	}
+	if patch.Enabled != nil {
+		args = append(args, *patch.Enabled)
+		query += fmt.Sprintf(", enabled = $%d", len(args))
+	}
+
+	args = append(args, id)
+	query += fmt.Sprintf(" WHERE id = $%d RETURNING id, url, secret, event_types, enabled, created_at", len(args))
+
+	var w Webhook
+	err := s.DB.QueryRowContext(ctx, query, args...).Scan(&w.ID, &w.URL, &w.Secret, pq.Array(&w.EventTypes), &w.Enabled, &w.CreatedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrWebhookNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &w, nil
+}
+
+func (s *Store) DeleteWebhook(ctx context.Context, id int) error {
+	res, err := s.DB.ExecContext(ctx, `DELETE FROM webhooks WHERE id = $1`, id)
+	if err != nil {
+		return err
+	}
+
+	rows, _ := res.RowsAffected()
+	if rows == 0 {
+		return ErrWebhookNotFound
+	}
+
+	return nil
+}
+
+func (s *Store) GetWebhookDeliveries(ctx context.Context, webhookID int, limit int, offset int) ([]WebhookDelivery, error) {
+	query := `
+		SELECT d.id, d.webhook_id, w.url, e.event_type, e.payload, d.status, d.attempts,
+		       d.last_status_code, d.last_error, d.next_attempt_at, d.created_at, d.finished_at
+		FROM webhook_deliveries d
+		JOIN webhooks w ON w.id = d.webhook_id
+		JOIN outbox_events e ON e.id = d.outbox_event_id
+		WHERE d.webhook_id = $1
+		ORDER BY d.id DESC
+		LIMIT $2 OFFSET $3
+	`
+	rows, err := s.DB.QueryContext(ctx, query, webhookID, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var result []WebhookDelivery
+	for rows.Next() {
+		var d WebhookDelivery
+		var payload []byte
+		if err := rows.Scan(
+			&d.ID, &d.WebhookID, &d.URL, &d.EventType, &payload, &d.Status, &d.Attempts,
+			&d.LastStatus, &d.LastError, &d.NextAttemptAt, &d.CreatedAt, &d.FinishedAt,
+		); err != nil {
+			return nil, err
+		}
+		d.Payload = payload
+		if d.Status != "pending" {
+			d.NextAttemptAt = nil
+		}
+		result = append(result, d)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+func (s *Store) EnqueueTestWebhookEvent(ctx context.Context, webhookID int) (int64, error) {
+	tx, err := s.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	var enabled bool
+	if err := tx.QueryRowContext(ctx, `SELECT enabled FROM webhooks WHERE id = $1`, webhookID).Scan(&enabled); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return 0, ErrWebhookNotFound
+		}
+		return 0, err
+	}
+	if !enabled {
+		return 0, ErrWebhookDisabled
+	}
+
+	eventID, err := enqueueOutboxEvent(ctx, tx, "webhook.test", map[string]any{"webhook_id": webhookID})
+	if err != nil {
+		return 0, err
+	}
+
+	if _, err := tx.ExecContext(ctx, `UPDATE outbox_events SET dispatched_at = NOW() WHERE id = $1`, eventID); err != nil {
+		return 0, err
+	}
+
+	var deliveryID int64
+	query := `
+		INSERT INTO webhook_deliveries (webhook_id, outbox_event_id)
+		VALUES ($1, $2)
+		RETURNING id
+	`
+	if err := tx.QueryRowContext(ctx, query, webhookID, eventID).Scan(&deliveryID); err != nil {
+		return 0, err
+	}
+
+	return deliveryID, tx.Commit()
+}
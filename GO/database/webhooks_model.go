@@ -0,0 +1,55 @@
+package database
+
+import (
+	"encoding/json"
+	"errors"
+	"time"
+)
+
+type WebhookDelivery struct {
+	ID            int64           `json:"id"`
+	WebhookID     int             `json:"webhook_id"`
+	URL           string          `json:"url"`
+	Secret        string          `json:"-"`
+	EventType     string          `json:"event_type"`
+	Payload       json.RawMessage `json:"payload"`
+	Status        string          `json:"status"`
+	Attempts      int             `json:"attempts"`
+	LastStatus    *int            `json:"last_status_code,omitempty"`
+	LastError     *string         `json:"last_error,omitempty"`
+	NextAttemptAt *time.Time      `json:"next_attempt_at,omitempty"`
+	CreatedAt     time.Time       `json:"created_at"`
+	FinishedAt    *time.Time      `json:"finished_at,omitempty"`
+}
+
+type Webhook struct {
+	ID         int       `json:"id"`
+	URL        string    `json:"url"`
+	Secret     string    `json:"-"`
+	EventTypes []string  `json:"event_types"`
+	Enabled    bool      `json:"enabled"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+type WebhookPatch struct {
+	URL        *string
+	Secret     *string
+	EventTypes *[]string
+	Enabled    *bool
+}
+
+var WebhookEventTypes = []string{
+	"subscription.created",
+	"subscription.upgraded",
+	"subscription.downgrade_scheduled",
+	"subscription.rolled_back",
+	"subscription.updated",
+	"subscription.deleted",
+	"subscription.restored",
+	"subscription.price_changed",
+	"subscription.purged",
+	"budget.exceeded",
+}
+
+var ErrWebhookNotFound = errors.New("вебхук не найден")
+var ErrWebhookDisabled = errors.New("вебхук отключен")
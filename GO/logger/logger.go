@@ -1,28 +1,80 @@
 package logger
 
 import (
+	"context"
 	"fmt"
-	"log"
+	"io"
+	"log/slog"
 	"os"
-	"time"
-)
+	"slices"
 
-var (
-	infoLogger  = log.New(os.Stdout, "", 0)
-	warnLogger  = log.New(os.Stdout, "", 0)
-	errorLogger = log.New(os.Stderr, "", 0)
+	"github.com/go-chi/chi/v5"
 )
 
+// routeFields — параметры маршрута chi, которые попадают в каждую строку
+// лога запроса, если не были добавлены в контекст явно.
+var routeFields = []string{"user_id", "service_name"}
+
+var base = slog.New(&contextHandler{Handler: slog.NewTextHandler(os.Stdout, nil)})
+
+type fieldsKey struct{}
+
+// Setup задает формат (json или text) и минимальный уровень логов.
+func Setup(format string, level slog.Level) {
+	base = slog.New(&contextHandler{Handler: newHandler(os.Stdout, format, level)})
+}
+
+func newHandler(w io.Writer, format string, level slog.Level) slog.Handler {
+	opts := &slog.HandlerOptions{Level: level}
+	if format == "json" {
+		return slog.NewJSONHandler(w, opts)
+	}
+	return slog.NewTextHandler(w, opts)
+}
+
+// WithFields возвращает контекст, к логам которого добавляются указанные поля.
+func WithFields(ctx context.Context, attrs ...slog.Attr) context.Context {
+	fields, _ := ctx.Value(fieldsKey{}).([]slog.Attr)
+	return context.WithValue(ctx, fieldsKey{}, append(slices.Clip(fields), attrs...))
+}
+
+func Debug(msg string, args ...any) {
+	log(context.Background(), slog.LevelDebug, msg, args...)
+}
+
 func Info(msg string, args ...any) {
-	infoLogger.Printf("[%s] [INFO]  %s", time.Now().Format("2006-01-02 15:04:05"), format(msg, args...))
+	log(context.Background(), slog.LevelInfo, msg, args...)
 }
 
 func Warn(msg string, args ...any) {
-	warnLogger.Printf("[%s] [WARN]  %s", time.Now().Format("2006-01-02 15:04:05"), format(msg, args...))
+	log(context.Background(), slog.LevelWarn, msg, args...)
 }
 
 func Error(msg string, args ...any) {
-	errorLogger.Printf("[%s] [ERROR] %s", time.Now().Format("2006-01-02 15:04:05"), format(msg, args...))
+	log(context.Background(), slog.LevelError, msg, args...)
+}
+
+func DebugContext(ctx context.Context, msg string, args ...any) {
+	log(ctx, slog.LevelDebug, msg, args...)
+}
+
+func InfoContext(ctx context.Context, msg string, args ...any) {
+	log(ctx, slog.LevelInfo, msg, args...)
+}
+
+func WarnContext(ctx context.Context, msg string, args ...any) {
+	log(ctx, slog.LevelWarn, msg, args...)
+}
+
+func ErrorContext(ctx context.Context, msg string, args ...any) {
+	log(ctx, slog.LevelError, msg, args...)
+}
+
+func log(ctx context.Context, level slog.Level, msg string, args ...any) {
+	if !base.Enabled(ctx, level) {
+		return
+	}
+	base.Log(ctx, level, format(msg, args...))
 }
 
 func format(msg string, args ...any) string {
@@ -31,3 +83,33 @@ func format(msg string, args ...any) string {
 	}
 	return msg
 }
+
+// contextHandler дополняет записи полями из контекста запроса.
+type contextHandler struct {
+	slog.Handler
+}
+
+func (h *contextHandler) Handle(ctx context.Context, rec slog.Record) error {
+	fields, _ := ctx.Value(fieldsKey{}).([]slog.Attr)
+	rec.AddAttrs(fields...)
+
+	if rctx := chi.RouteContext(ctx); rctx != nil {
+		for _, key := range routeFields {
+			value := rctx.URLParam(key)
+			if value == "" || slices.ContainsFunc(fields, func(a slog.Attr) bool { return a.Key == key }) {
+				continue
+			}
+			rec.AddAttrs(slog.String(key, value))
+		}
+	}
+
+	return h.Handler.Handle(ctx, rec)
+}
+
+func (h *contextHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &contextHandler{Handler: h.Handler.WithAttrs(attrs)}
+}
+
+func (h *contextHandler) WithGroup(name string) slog.Handler {
+	return &contextHandler{Handler: h.Handler.WithGroup(name)}
+}
@@ -0,0 +1,18 @@
+package notify
+
+import (
+	"context"
+
+	"github.com/Halturshik/EM-test-task/GO/logger"
+)
+
+type LogNotifier struct{}
+
+func NewLogNotifier() *LogNotifier {
+	return &LogNotifier{}
+}
+
+func (LogNotifier) Notify(ctx context.Context, n Notification) error {
+	logger.Info("Уведомление пользователю %s (%s): %s. %s", n.UserID, n.Kind, n.Subject(), n.Text())
+	return nil
+}
@@ -0,0 +1,55 @@
+package notify
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	KindExpiry    = "expiry"
+	KindDowngrade = "downgrade"
+)
+
+type Notification struct {
+	Kind        string
+	UserID      uuid.UUID
+	Email       string
+	ServiceName string
+	Date        time.Time
+	Price       int
+	NewPrice    *int
+}
+
+type Notifier interface {
+	Notify(ctx context.Context, n Notification) error
+}
+
+var ErrNoRecipient = errors.New("у пользователя не указан адрес для уведомлений")
+
+func (n Notification) Subject() string {
+	switch n.Kind {
+	case KindDowngrade:
+		return fmt.Sprintf("Уровень подписки %s скоро изменится", n.ServiceName)
+	default:
+		return fmt.Sprintf("Подписка %s скоро закончится", n.ServiceName)
+	}
+}
+
+func (n Notification) Text() string {
+	switch n.Kind {
+	case KindDowngrade:
+		newPrice := 0
+		if n.NewPrice != nil {
+			newPrice = *n.NewPrice
+		}
+		return fmt.Sprintf("С %s уровень подписки %s будет понижен: стоимость изменится с %d до %d в месяц.",
+			n.Date.Format("02.01.2006"), n.ServiceName, n.Price, newPrice)
+	default:
+		return fmt.Sprintf("Подписка %s (стоимость %d в месяц) действует до %s. Продлите ее, чтобы не потерять доступ к сервису.",
+			n.ServiceName, n.Price, n.Date.Format("02.01.2006"))
+	}
+}
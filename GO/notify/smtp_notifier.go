@@ -0,0 +1,120 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"mime"
+	"net"
+	"net/smtp"
+	"time"
+)
+
+type SMTPNotifier struct {
+	Addr     string
+	Host     string
+	Username string
+	Password string
+	From     string
+	Timeout  time.Duration
+}
+
+func NewSMTPNotifier(host, port, username, password, from string, timeout time.Duration) *SMTPNotifier {
+	return &SMTPNotifier{
+		Addr:     net.JoinHostPort(host, port),
+		Host:     host,
+		Username: username,
+		Password: password,
+		From:     from,
+		Timeout:  timeout,
+	}
+}
+
+func (s *SMTPNotifier) Notify(ctx context.Context, n Notification) error {
+	if n.Email == "" {
+		return ErrNoRecipient
+	}
+
+	if err := s.send(ctx, n.Email, s.buildMessage(n)); err != nil {
+		return fmt.Errorf("ошибка отправки письма через %s: %w", s.Addr, err)
+	}
+
+	return nil
+}
+
+// send повторяет smtp.SendMail, но весь сеанс ограничен Timeout и прерывается
+// при отмене ctx.
+func (s *SMTPNotifier) send(ctx context.Context, to string, msg []byte) error {
+	dialer := net.Dialer{Timeout: s.Timeout}
+	conn, err := dialer.DialContext(ctx, "tcp", s.Addr)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	if err := conn.SetDeadline(time.Now().Add(s.Timeout)); err != nil {
+		return err
+	}
+	stop := context.AfterFunc(ctx, func() {
+		conn.SetDeadline(time.Now())
+	})
+	defer stop()
+
+	c, err := smtp.NewClient(conn, s.Host)
+	if err != nil {
+		return err
+	}
+	defer c.Close()
+
+	if ok, _ := c.Extension("STARTTLS"); ok {
+		if err := c.StartTLS(&tls.Config{ServerName: s.Host}); err != nil {
+			return err
+		}
+	}
+
+	if s.Username != "" {
+		if ok, _ := c.Extension("AUTH"); !ok {
+			return errors.New("сервер не поддерживает авторизацию")
+		}
+		if err := c.Auth(smtp.PlainAuth("", s.Username, s.Password, s.Host)); err != nil {
+			return err
+		}
+	}
+
+	if err := c.Mail(s.From); err != nil {
+		return err
+	}
+	if err := c.Rcpt(to); err != nil {
+		return err
+	}
+
+	w, err := c.Data()
+	if err != nil {
+		return err
+	}
+	if _, err := w.Write(msg); err != nil {
+		return err
+	}
+	if err := w.Close(); err != nil {
+		return err
+	}
+
+	return c.Quit()
+}
+
+func (s *SMTPNotifier) buildMessage(n Notification) []byte {
+	var msg bytes.Buffer
+	fmt.Fprintf(&msg, "From: %s\r\n", s.From)
+	fmt.Fprintf(&msg, "To: %s\r\n", n.Email)
+	fmt.Fprintf(&msg, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", n.Subject()))
+	fmt.Fprintf(&msg, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	msg.WriteString("MIME-Version: 1.0\r\n")
+	msg.WriteString("Content-Type: text/plain; charset=UTF-8\r\n")
+	msg.WriteString("Content-Transfer-Encoding: 8bit\r\n")
+	msg.WriteString("\r\n")
+	msg.WriteString(n.Text())
+	msg.WriteString("\r\n")
+	return msg.Bytes()
+}
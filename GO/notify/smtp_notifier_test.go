@@ -0,0 +1,220 @@
+package notify
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"io"
+	"mime"
+	"net"
+	"net/textproto"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type receivedMail struct {
+	from string
+	to   string
+	data string
+}
+
+// startFakeSMTP запускает минимальный SMTP-сервер без STARTTLS и AUTH,
+// который принимает одно письмо и передает его в канал.
+func startFakeSMTP(t *testing.T) (string, <-chan receivedMail) {
+	t.Helper()
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("не удалось запустить сервер: %v", err)
+	}
+	t.Cleanup(func() { ln.Close() })
+
+	mails := make(chan receivedMail, 1)
+	go func() {
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+
+		tp := textproto.NewConn(conn)
+		var mail receivedMail
+
+		tp.PrintfLine("220 localhost ESMTP")
+		for {
+			line, err := tp.ReadLine()
+			if err != nil {
+				return
+			}
+			cmd := strings.ToUpper(line)
+			switch {
+			case strings.HasPrefix(cmd, "EHLO"), strings.HasPrefix(cmd, "HELO"):
+				tp.PrintfLine("250 localhost")
+			case strings.HasPrefix(cmd, "MAIL FROM:"):
+				mail.from = strings.Trim(line[len("MAIL FROM:"):], "<> ")
+				tp.PrintfLine("250 OK")
+			case strings.HasPrefix(cmd, "RCPT TO:"):
+				mail.to = strings.Trim(line[len("RCPT TO:"):], "<> ")
+				tp.PrintfLine("250 OK")
+			case cmd == "DATA":
+				tp.PrintfLine("354 End data with <CR><LF>.<CR><LF>")
+				data, err := tp.ReadDotBytes()
+				if err != nil {
+					return
+				}
+				mail.data = string(data)
+				tp.PrintfLine("250 OK")
+			case cmd == "QUIT":
+				tp.PrintfLine("221 Bye")
+				mails <- mail
+				return
+			default:
+				tp.PrintfLine("502 Command not implemented")
+			}
+		}
+	}()
+
+	return ln.Addr().String(), mails
+}
+
+// startStalledSMTP принимает подключения, но ничего не отвечает.
+func startStalledSMTP(t *testing.T) string {
+	t.Helper()
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("не удалось запустить сервер: %v", err)
+	}
+	t.Cleanup(func() { ln.Close() })
+
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer conn.Close()
+				io.Copy(io.Discard, conn)
+			}()
+		}
+	}()
+
+	return ln.Addr().String()
+}
+
+func newTestNotifier(t *testing.T, addr string, timeout time.Duration) *SMTPNotifier {
+	t.Helper()
+
+	host, port, err := net.SplitHostPort(addr)
+	if err != nil {
+		t.Fatalf("некорректный адрес %s: %v", addr, err)
+	}
+	return NewSMTPNotifier(host, port, "", "", "noreply@example.com", timeout)
+}
+
+func testNotification() Notification {
+	return Notification{
+		Kind:        KindExpiry,
+		UserID:      uuid.New(),
+		Email:       "user@example.com",
+		ServiceName: "Yandex Plus",
+		Date:        time.Date(2025, 12, 31, 0, 0, 0, 0, time.UTC),
+		Price:       400,
+	}
+}
+
+func TestSMTPNotifierDeliversMessage(t *testing.T) {
+	addr, mails := startFakeSMTP(t)
+	s := newTestNotifier(t, addr, 5*time.Second)
+	n := testNotification()
+
+	if err := s.Notify(context.Background(), n); err != nil {
+		t.Fatalf("Notify: %v", err)
+	}
+
+	var mail receivedMail
+	select {
+	case mail = <-mails:
+	case <-time.After(5 * time.Second):
+		t.Fatal("сервер не получил письмо")
+	}
+
+	if mail.from != s.From {
+		t.Errorf("MAIL FROM = %q, ожидалось %q", mail.from, s.From)
+	}
+	if mail.to != n.Email {
+		t.Errorf("RCPT TO = %q, ожидалось %q", mail.to, n.Email)
+	}
+
+	msg, err := textproto.NewReader(bufio.NewReader(strings.NewReader(mail.data))).ReadMIMEHeader()
+	if err != nil {
+		t.Fatalf("не удалось разобрать заголовки письма: %v", err)
+	}
+	if got := msg.Get("From"); got != s.From {
+		t.Errorf("From = %q, ожидалось %q", got, s.From)
+	}
+	if got := msg.Get("To"); got != n.Email {
+		t.Errorf("To = %q, ожидалось %q", got, n.Email)
+	}
+	subject, err := new(mime.WordDecoder).DecodeHeader(msg.Get("Subject"))
+	if err != nil {
+		t.Fatalf("не удалось декодировать тему: %v", err)
+	}
+	if subject != n.Subject() {
+		t.Errorf("Subject = %q, ожидалось %q", subject, n.Subject())
+	}
+
+	_, body, ok := strings.Cut(mail.data, "\n\n")
+	if !ok {
+		t.Fatal("в письме нет тела")
+	}
+	if strings.TrimSpace(body) != n.Text() {
+		t.Errorf("тело письма = %q, ожидалось %q", strings.TrimSpace(body), n.Text())
+	}
+}
+
+func TestSMTPNotifierRequiresRecipient(t *testing.T) {
+	s := NewSMTPNotifier("127.0.0.1", "1", "", "", "noreply@example.com", time.Second)
+	n := testNotification()
+	n.Email = ""
+
+	if err := s.Notify(context.Background(), n); !errors.Is(err, ErrNoRecipient) {
+		t.Fatalf("Notify = %v, ожидалось %v", err, ErrNoRecipient)
+	}
+}
+
+func TestSMTPNotifierTimeout(t *testing.T) {
+	addr := startStalledSMTP(t)
+	s := newTestNotifier(t, addr, 200*time.Millisecond)
+
+	start := time.Now()
+	err := s.Notify(context.Background(), testNotification())
+	var netErr net.Error
+	if !errors.As(err, &netErr) || !netErr.Timeout() {
+		t.Fatalf("Notify = %v, ожидалась ошибка таймаута", err)
+	}
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Fatalf("отправка заняла %v при таймауте %v", elapsed, s.Timeout)
+	}
+}
+
+func TestSMTPNotifierContextCancel(t *testing.T) {
+	addr := startStalledSMTP(t)
+	s := newTestNotifier(t, addr, time.Minute)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(200*time.Millisecond, cancel)
+
+	start := time.Now()
+	err := s.Notify(ctx, testNotification())
+	if err == nil {
+		t.Fatal("ожидалась ошибка после отмены контекста")
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Fatalf("отправка не прервалась после отмены контекста: %v", elapsed)
+	}
+}
@@ -2,16 +2,82 @@ package config
 
 import (
 	"fmt"
+	"log/slog"
 	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
 )
 
 type Config struct {
-	DBHost     string
-	DBPort     string
-	DBUser     string
-	DBPassword string
-	DBName     string
-	AppPort    string
+	DBHost         string
+	DBPort         string
+	DBUser         string
+	DBPassword     string
+	DBName         string
+	AppPort        string
+	IdempotencyTTL time.Duration
+
+	SoftDeleteRetention     time.Duration
+	SoftDeletePurgeInterval time.Duration
+
+	WebhookPollInterval time.Duration
+	WebhookTimeout      time.Duration
+	WebhookMaxAttempts  int
+	WebhookBackoffBase  time.Duration
+	WebhookBackoffMax   time.Duration
+	WebhookRetention    time.Duration
+
+	SSEPollInterval time.Duration
+
+	Notifier          string
+	ReminderDaysAhead int
+	ReminderInterval  time.Duration
+	SMTPHost          string
+	SMTPPort          string
+	SMTPUsername      string
+	SMTPPassword      string
+	SMTPFrom          string
+	SMTPTimeout       time.Duration
+
+	BootstrapAdminKey string
+
+	JWTHS256Secret string
+	JWTJWKSFile    string
+	JWTIssuer      string
+	JWTAudience    string
+
+	RBACPolicyTTL time.Duration
+
+	RateLimitIP      RateLimit
+	RateLimitDefault RateLimit
+	RateLimitWrite   RateLimit
+	RateLimitReports RateLimit
+
+	MaxBodyBytes            int
+	ServerReadHeaderTimeout time.Duration
+	ServerReadTimeout       time.Duration
+	ServerWriteTimeout      time.Duration
+	ServerIdleTimeout       time.Duration
+	HandlerTimeout          time.Duration
+
+	CORSAllowedOrigins   []string
+	CORSAllowedMethods   []string
+	CORSAllowedHeaders   []string
+	CORSExposedHeaders   []string
+	CORSAllowCredentials bool
+	CORSMaxAge           time.Duration
+
+	TracingExporter string
+
+	LogFormat string
+	LogLevel  slog.Level
+}
+
+type RateLimit struct {
+	Requests int
+	Period   time.Duration
 }
 
 func LoadConfig() (*Config, error) {
@@ -22,6 +88,20 @@ func LoadConfig() (*Config, error) {
 		DBPassword: os.Getenv("DB_PASSWORD"),
 		DBName:     os.Getenv("DB_NAME"),
 		AppPort:    os.Getenv("APP_PORT"),
+
+		Notifier:     os.Getenv("NOTIFIER"),
+		SMTPHost:     os.Getenv("SMTP_HOST"),
+		SMTPPort:     os.Getenv("SMTP_PORT"),
+		SMTPUsername: os.Getenv("SMTP_USERNAME"),
+		SMTPPassword: os.Getenv("SMTP_PASSWORD"),
+		SMTPFrom:     os.Getenv("SMTP_FROM"),
+
+		BootstrapAdminKey: os.Getenv("BOOTSTRAP_ADMIN_KEY"),
+
+		JWTHS256Secret: os.Getenv("JWT_HS256_SECRET"),
+		JWTJWKSFile:    os.Getenv("JWT_JWKS_FILE"),
+		JWTIssuer:      os.Getenv("JWT_ISSUER"),
+		JWTAudience:    os.Getenv("JWT_AUDIENCE"),
 	}
 
 	if cfg.DBHost == "" {
@@ -43,5 +123,212 @@ func LoadConfig() (*Config, error) {
 		cfg.AppPort = "8080"
 	}
 
+	var err error
+	if cfg.IdempotencyTTL, err = getDuration("IDEMPOTENCY_TTL", 24*time.Hour); err != nil {
+		return nil, err
+	}
+	if cfg.SoftDeleteRetention, err = getDuration("SOFT_DELETE_RETENTION", 30*24*time.Hour); err != nil {
+		return nil, err
+	}
+	if cfg.SoftDeletePurgeInterval, err = getDuration("SOFT_DELETE_PURGE_INTERVAL", 24*time.Hour); err != nil {
+		return nil, err
+	}
+
+	if cfg.WebhookPollInterval, err = getDuration("WEBHOOK_POLL_INTERVAL", 5*time.Second); err != nil {
+		return nil, err
+	}
+	if cfg.WebhookTimeout, err = getDuration("WEBHOOK_TIMEOUT", 10*time.Second); err != nil {
+		return nil, err
+	}
+	if cfg.WebhookMaxAttempts, err = getInt("WEBHOOK_MAX_ATTEMPTS", 8); err != nil {
+		return nil, err
+	}
+	if cfg.WebhookBackoffBase, err = getDuration("WEBHOOK_BACKOFF_BASE", 30*time.Second); err != nil {
+		return nil, err
+	}
+	if cfg.WebhookBackoffMax, err = getDuration("WEBHOOK_BACKOFF_MAX", 6*time.Hour); err != nil {
+		return nil, err
+	}
+	if cfg.WebhookRetention, err = getDuration("WEBHOOK_RETENTION", 30*24*time.Hour); err != nil {
+		return nil, err
+	}
+
+	if cfg.SSEPollInterval, err = getDuration("SSE_POLL_INTERVAL", time.Second); err != nil {
+		return nil, err
+	}
+
+	if cfg.ReminderDaysAhead, err = getInt("REMINDER_DAYS_AHEAD", 7); err != nil {
+		return nil, err
+	}
+	if cfg.ReminderInterval, err = getDuration("REMINDER_INTERVAL", time.Hour); err != nil {
+		return nil, err
+	}
+	if cfg.SMTPTimeout, err = getDuration("SMTP_TIMEOUT", 30*time.Second); err != nil {
+		return nil, err
+	}
+
+	if cfg.BootstrapAdminKey != "" && len(cfg.BootstrapAdminKey) < 32 {
+		return nil, fmt.Errorf("BOOTSTRAP_ADMIN_KEY должен содержать не менее 32 символов")
+	}
+
+	if cfg.JWTHS256Secret != "" && len(cfg.JWTHS256Secret) < 32 {
+		return nil, fmt.Errorf("JWT_HS256_SECRET должен содержать не менее 32 символов")
+	}
+	if cfg.RBACPolicyTTL, err = getDuration("RBAC_POLICY_TTL", time.Minute); err != nil {
+		return nil, err
+	}
+
+	if cfg.MaxBodyBytes, err = getInt("MAX_BODY_BYTES", 1<<20); err != nil {
+		return nil, err
+	}
+	if cfg.ServerReadHeaderTimeout, err = getDuration("SERVER_READ_HEADER_TIMEOUT", 5*time.Second); err != nil {
+		return nil, err
+	}
+	if cfg.ServerReadTimeout, err = getDuration("SERVER_READ_TIMEOUT", 15*time.Second); err != nil {
+		return nil, err
+	}
+	if cfg.ServerWriteTimeout, err = getDuration("SERVER_WRITE_TIMEOUT", 30*time.Second); err != nil {
+		return nil, err
+	}
+	if cfg.ServerIdleTimeout, err = getDuration("SERVER_IDLE_TIMEOUT", 60*time.Second); err != nil {
+		return nil, err
+	}
+	if cfg.HandlerTimeout, err = getDuration("HANDLER_TIMEOUT", 20*time.Second); err != nil {
+		return nil, err
+	}
+
+	cfg.CORSAllowedOrigins = getList("CORS_ALLOWED_ORIGINS", nil)
+	cfg.CORSAllowedMethods = getList("CORS_ALLOWED_METHODS", []string{"GET", "POST", "PUT", "PATCH", "DELETE"})
+	cfg.CORSAllowedHeaders = getList("CORS_ALLOWED_HEADERS", []string{"Authorization", "Content-Type", "X-API-Key", "X-Request-ID", "Idempotency-Key", "If-Match", "Last-Event-ID"})
+	cfg.CORSExposedHeaders = getList("CORS_EXPOSED_HEADERS", []string{"ETag", "Retry-After", "RateLimit-Limit", "RateLimit-Remaining", "RateLimit-Reset", "Idempotent-Replayed", "X-Request-ID"})
+	cfg.CORSAllowCredentials = os.Getenv("CORS_ALLOW_CREDENTIALS") == "true"
+	if cfg.CORSMaxAge, err = getDuration("CORS_MAX_AGE", 10*time.Minute); err != nil {
+		return nil, err
+	}
+	if cfg.CORSAllowCredentials && slices.Contains(cfg.CORSAllowedOrigins, "*") {
+		return nil, fmt.Errorf("CORS_ALLOW_CREDENTIALS=true нельзя сочетать с CORS_ALLOWED_ORIGINS=*")
+	}
+
+	if cfg.RateLimitIP, err = getRateLimit("RATE_LIMIT_IP", RateLimit{Requests: 600, Period: time.Minute}); err != nil {
+		return nil, err
+	}
+	if cfg.RateLimitDefault, err = getRateLimit("RATE_LIMIT_DEFAULT", RateLimit{Requests: 300, Period: time.Minute}); err != nil {
+		return nil, err
+	}
+	if cfg.RateLimitWrite, err = getRateLimit("RATE_LIMIT_WRITE", RateLimit{Requests: 30, Period: time.Minute}); err != nil {
+		return nil, err
+	}
+	if cfg.RateLimitReports, err = getRateLimit("RATE_LIMIT_REPORTS", RateLimit{Requests: 20, Period: time.Minute}); err != nil {
+		return nil, err
+	}
+
+	cfg.LogFormat = os.Getenv("LOG_FORMAT")
+	switch cfg.LogFormat {
+	case "":
+		cfg.LogFormat = "json"
+	case "json", "text":
+	default:
+		return nil, fmt.Errorf("LOG_FORMAT указан некорректно: %q (допустимо json или text)", cfg.LogFormat)
+	}
+	if value := os.Getenv("LOG_LEVEL"); value != "" {
+		if err := cfg.LogLevel.UnmarshalText([]byte(value)); err != nil {
+			return nil, fmt.Errorf("LOG_LEVEL указан некорректно: %q (допустимо debug, info, warn или error)", value)
+		}
+	}
+
+	cfg.TracingExporter = os.Getenv("TRACING_EXPORTER")
+	switch cfg.TracingExporter {
+	case "":
+		cfg.TracingExporter = "none"
+	case "none", "otlp", "stdout":
+	default:
+		return nil, fmt.Errorf("TRACING_EXPORTER указан некорректно: %q (допустимо none, otlp или stdout)", cfg.TracingExporter)
+	}
+
+	switch cfg.Notifier {
+	case "":
+		cfg.Notifier = "log"
+	case "log":
+	case "smtp":
+		if cfg.SMTPHost == "" {
+			return nil, fmt.Errorf("SMTP_HOST не указан")
+		}
+		if cfg.SMTPFrom == "" {
+			return nil, fmt.Errorf("SMTP_FROM не указан")
+		}
+		if cfg.SMTPPort == "" {
+			cfg.SMTPPort = "25"
+		}
+	default:
+		return nil, fmt.Errorf("NOTIFIER указан некорректно: %q (допустимо log или smtp)", cfg.Notifier)
+	}
+
 	return cfg, nil
 }
+
+func getDuration(key string, def time.Duration) (time.Duration, error) {
+	value := os.Getenv(key)
+	if value == "" {
+		return def, nil
+	}
+
+	d, err := time.ParseDuration(value)
+	if err != nil || d <= 0 {
+		return 0, fmt.Errorf("%s указан некорректно: %q", key, value)
+	}
+
+	return d, nil
+}
+
+func getInt(key string, def int) (int, error) {
+	value := os.Getenv(key)
+	if value == "" {
+		return def, nil
+	}
+
+	n, err := strconv.Atoi(value)
+	if err != nil || n <= 0 {
+		return 0, fmt.Errorf("%s указан некорректно: %q", key, value)
+	}
+
+	return n, nil
+}
+
+func getRateLimit(key string, def RateLimit) (RateLimit, error) {
+	value := os.Getenv(key)
+	if value == "" {
+		return def, nil
+	}
+	if value == "off" {
+		return RateLimit{}, nil
+	}
+
+	requests, period, found := strings.Cut(value, "/")
+	n, err := strconv.Atoi(requests)
+	if !found || err != nil || n <= 0 {
+		return RateLimit{}, fmt.Errorf("%s указан некорректно: %q (ожидается <запросов>/<период>, например 60/1m)", key, value)
+	}
+
+	d, err := time.ParseDuration(period)
+	if err != nil || d <= 0 {
+		return RateLimit{}, fmt.Errorf("%s указан некорректно: %q (ожидается <запросов>/<период>, например 60/1m)", key, value)
+	}
+
+	return RateLimit{Requests: n, Period: d}, nil
+}
+
+func getList(key string, def []string) []string {
+	value := os.Getenv(key)
+	if value == "" {
+		return def
+	}
+
+	var result []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			result = append(result, item)
+		}
+	}
+
+	return result
+}
@@ -11,6 +11,8 @@ import (
 	"github.com/Halturshik/EM-test-task/GO/api"
 	"github.com/Halturshik/EM-test-task/GO/database"
 	"github.com/Halturshik/EM-test-task/GO/logger"
+	"github.com/Halturshik/EM-test-task/GO/notify"
+	"github.com/Halturshik/EM-test-task/GO/tracing"
 	"github.com/Halturshik/EM-test-task/config"
 	_ "github.com/Halturshik/EM-test-task/docs"
 	"github.com/go-chi/chi/v5"
@@ -26,27 +28,74 @@ func main() {
 	cfg, err := config.LoadConfig()
 	if err != nil {
 		logger.Error("Ошибка загрузки конфигурации: %v", err)
+		os.Exit(1)
+	}
+
+	logger.Setup(cfg.LogFormat, cfg.LogLevel)
+
+	jwtVerifier, err := api.NewJWTVerifier(cfg)
+	if err != nil {
+		logger.Error("Ошибка настройки проверки JWT: %v", err)
+		os.Exit(1)
+	}
+
+	shutdownTracing, err := tracing.Setup(context.Background(), cfg.TracingExporter, api.BuildVersion)
+	if err != nil {
+		logger.Error("Ошибка настройки трассировки: %v", err)
 	}
 
 	dbConnection, err := database.ConnectDB(cfg)
 	if err != nil {
 		logger.Error("Ошибка при подключении к БД: %v", err)
+		os.Exit(1)
 	}
 	defer dbConnection.Close()
 
 	store := database.NewStore(dbConnection)
+	if err := store.LoadExpectedMigrationVersion(); err != nil {
+		logger.Error("Ошибка чтения каталога миграций: %v", err)
+		os.Exit(1)
+	}
+	api.RegisterDBMetrics(dbConnection)
+
+	if cfg.BootstrapAdminKey != "" {
+		if err := api.BootstrapAdminKey(context.Background(), store, cfg.BootstrapAdminKey); err != nil {
+			logger.Error("Ошибка при создании начального API-ключа администратора: %v", err)
+		}
+	}
+
+	var notifier notify.Notifier = notify.NewLogNotifier()
+	if cfg.Notifier == "smtp" {
+		notifier = notify.NewSMTPNotifier(cfg.SMTPHost, cfg.SMTPPort, cfg.SMTPUsername, cfg.SMTPPassword, cfg.SMTPFrom, cfg.SMTPTimeout)
+	}
+
+	// Фоновые задачи запускаются только после проверки всей конфигурации:
+	// os.Exit при ошибке не дал бы им корректно остановиться.
 	api.StartMonthlySync(store)
-	apiServer := api.NewAPI(store)
+	api.StartDeletedSubsPurge(store, cfg)
+	api.StartWebhookDispatcher(store, cfg)
+	api.StartReminderScheduler(store, notifier, cfg)
+
+	apiServer := api.NewAPI(store, cfg, jwtVerifier)
 
 	r := chi.NewRouter()
 
+	r.Use(api.TracingMiddleware)
+	r.Use(api.RequestIDMiddleware)
 	r.Use(api.LoggingMiddleware)
+	r.Use(api.CORSMiddleware(cfg))
+	r.Use(api.MaxBodyMiddleware(int64(cfg.MaxBodyBytes)))
+	r.Use(api.AuditContextMiddleware)
 
 	apiServer.Init(r)
 
 	srv := &http.Server{
-		Addr:    ":" + cfg.AppPort,
-		Handler: r,
+		Addr:              ":" + cfg.AppPort,
+		Handler:           r,
+		ReadHeaderTimeout: cfg.ServerReadHeaderTimeout,
+		ReadTimeout:       cfg.ServerReadTimeout,
+		WriteTimeout:      cfg.ServerWriteTimeout,
+		IdleTimeout:       cfg.ServerIdleTimeout,
 	}
 
 	stop := make(chan os.Signal, 1)
@@ -72,4 +121,13 @@ func main() {
 	}
 
 	api.StopMonthlySync()
+	api.StopDeletedSubsPurge()
+	api.StopWebhookDispatcher()
+	api.StopReminderScheduler()
+
+	if shutdownTracing != nil {
+		if err := shutdownTracing(ctx); err != nil {
+			logger.Error("Ошибка при остановке трассировки: %v", err)
+		}
+	}
 }